@@ -2,33 +2,37 @@ package api
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/gurukanth/simplebank/db/sqlc"
+	"github.com/gurukanth/simplebank/token"
 )
 
+var errAccountNotOwned = errors.New("account doesn't belong to the authenticated user")
+
 type createAccountRequest struct {
-	Owner    string `json:"owner" binding:"required"`
-	Currency string `json:"currency" binding:"required,oneof=USD EUR INR"`
+	Currency string `json:"currency" binding:"required,currency"`
 }
 
 func (server *Server) createAccount(ctx *gin.Context) {
 	var req createAccountRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	arg := db.CreateAccountParams{
-		Owner:    req.Owner,
+		Owner:    authPayload.Username,
 		Balance:  0,
 		Currency: req.Currency,
 	}
 
 	account, err := server.store.CreateAccount(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -42,47 +46,102 @@ type getAccountRequest struct {
 func (server *Server) getAccount(ctx *gin.Context) {
 	var req getAccountRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	account, err := server.store.GetAccount(ctx, req.ID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			respondError(ctx, http.StatusNotFound, err)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if account.Owner != authPayload.Username {
+		respondError(ctx, http.StatusForbidden, errAccountNotOwned)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, account)
 }
 
+// listAccountsRequest pages either with page_id, kept for existing clients,
+// or with the cursor returned by a previous call
 type listAccountsRequest struct {
-	PageId   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=100"`
+	PageId   int32  `form:"page_id" binding:"omitempty,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=100"`
+	Cursor   string `form:"cursor"`
 }
 
 func (server *Server) listAccounts(ctx *gin.Context) {
 	var req listAccountsRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if req.PageId == 0 {
+		server.listAccountsByCursor(ctx, req)
 		return
 	}
 
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	accounts, err := server.store.ListAccounts(ctx, db.ListAccountsParams{
+		Owner:  authPayload.Username,
 		Limit:  req.PageSize,
 		Offset: (req.PageId - 1) * req.PageSize,
 	})
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			respondError(ctx, http.StatusNotFound, err)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, accounts)
 }
+
+func (server *Server) listAccountsByCursor(ctx *gin.Context, req listAccountsRequest) {
+	var cursor pageCursor
+	if req.Cursor != "" {
+		var err error
+		if cursor, err = server.cursors.decode(req.Cursor); err != nil {
+			respondError(ctx, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	var accounts []db.Account
+	var err error
+	if cursor.Before {
+		accounts, err = server.store.ListAccountsBefore(ctx, db.ListAccountsBeforeParams{
+			Owner:      authPayload.Username,
+			BeforeID:   cursor.ID,
+			LimitCount: req.PageSize + 1,
+		})
+		reverse(accounts)
+	} else {
+		accounts, err = server.store.ListAccountsAfter(ctx, db.ListAccountsAfterParams{
+			Owner:      authPayload.Username,
+			AfterID:    cursor.ID,
+			LimitCount: req.PageSize + 1,
+		})
+	}
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	page := newCursorPage(server.cursors, accounts, int(req.PageSize), cursor, req.Cursor != "", func(account db.Account) pageCursor {
+		return pageCursor{ID: account.ID}
+	})
+	ctx.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var errNonPositiveAmount = errors.New("amount must be greater than 0")
+
+var decimalAmountRegexp = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// moneyAmount is an amount of money sent by a client, either as an integer
+// number of minor units (1050) or as a decimal string in major units
+// ("10.50"). The string form is resolved once the currency is known, since
+// its exponent decides how many decimal places are allowed.
+type moneyAmount struct {
+	minor   int64
+	decimal string
+}
+
+// minorAmount returns an amount given in minor units
+func minorAmount(n int64) moneyAmount {
+	return moneyAmount{minor: n}
+}
+
+func (amount *moneyAmount) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*amount = moneyAmount{}
+		return nil
+	}
+
+	if data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		if !decimalAmountRegexp.MatchString(s) {
+			return fmt.Errorf("amount %q is not a decimal number", s)
+		}
+		*amount = moneyAmount{decimal: s}
+		return nil
+	}
+
+	n, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return fmt.Errorf("amount %s must be a whole number of minor units or a decimal string", data)
+	}
+	*amount = moneyAmount{minor: n}
+	return nil
+}
+
+func (amount moneyAmount) MarshalJSON() ([]byte, error) {
+	if amount.decimal != "" {
+		return json.Marshal(amount.decimal)
+	}
+	return json.Marshal(amount.minor)
+}
+
+// minorUnits returns the amount in minor units of a currency whose minor
+// unit has exponent decimal places
+func (amount moneyAmount) minorUnits(exponent int16) (int64, error) {
+	if amount.decimal == "" {
+		return amount.minor, nil
+	}
+
+	whole, frac, _ := strings.Cut(amount.decimal, ".")
+	for len(frac) > int(exponent) && strings.HasSuffix(frac, "0") {
+		frac = strings.TrimSuffix(frac, "0")
+	}
+	if len(frac) > int(exponent) {
+		return 0, fmt.Errorf("amount %s has more than %d decimal places", amount.decimal, exponent)
+	}
+
+	n, err := strconv.ParseInt(whole+frac+strings.Repeat("0", int(exponent)-len(frac)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("amount %s is out of range", amount.decimal)
+	}
+	return n, nil
+}
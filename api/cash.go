@@ -0,0 +1,96 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gurukanth/simplebank/db/sqlc"
+)
+
+type cashRequest struct {
+	Amount   moneyAmount `json:"amount"`
+	Currency string      `json:"currency" binding:"required,currency"`
+}
+
+func (server *Server) createDeposit(ctx *gin.Context) {
+	account, amount, valid := server.bindCashRequest(ctx)
+	if !valid {
+		return
+	}
+
+	result, err := server.store.DepositTx(ctx, db.DepositTxParams{
+		AccountID: account.ID,
+		Amount:    amount,
+	})
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (server *Server) createWithdrawal(ctx *gin.Context) {
+	account, amount, valid := server.bindCashRequest(ctx)
+	if !valid {
+		return
+	}
+
+	result, err := server.store.WithdrawTx(ctx, db.WithdrawTxParams{
+		AccountID: account.ID,
+		Amount:    amount,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			respondError(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// bindCashRequest validates a deposit or withdrawal request against the
+// account in the URI and returns the amount in minor units. Cash is handled
+// by tellers on behalf of the account owner, so the account may belong to
+// anyone.
+func (server *Server) bindCashRequest(ctx *gin.Context) (db.Account, int64, bool) {
+	var uri getAccountRequest
+	var req cashRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return db.Account{}, 0, false
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return db.Account{}, 0, false
+	}
+
+	amount, valid := server.resolveAmount(ctx, req.Amount, req.Currency)
+	if !valid {
+		return db.Account{}, 0, false
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, http.StatusNotFound, err)
+			return account, 0, false
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return account, 0, false
+	}
+
+	if account.Currency != req.Currency {
+		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", account.ID, account.Currency, req.Currency)
+		respondError(ctx, http.StatusBadRequest, err)
+		return account, 0, false
+	}
+
+	return account, amount, true
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gurukanth/simplebank/db/sqlc"
+)
+
+// currencyRegistry caches the currencies table so that request validation
+// does not query Postgres on every request. Changes made through this server
+// are applied immediately; changes made elsewhere are picked up once the
+// cache is older than ttl.
+type currencyRegistry struct {
+	store    db.Store
+	ttl      time.Duration
+	mu       sync.RWMutex
+	byCode   map[string]db.Currency
+	loadedAt time.Time
+}
+
+func newCurrencyRegistry(store db.Store, ttl time.Duration) *currencyRegistry {
+	return &currencyRegistry{
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+// get returns the currency if it exists and is enabled
+func (registry *currencyRegistry) get(ctx context.Context, code string) (db.Currency, bool, error) {
+	currencies, err := registry.load(ctx)
+	if err != nil {
+		return db.Currency{}, false, err
+	}
+
+	currency, ok := currencies[code]
+	return currency, ok && currency.Enabled, nil
+}
+
+// load returns the cached currencies, reading them again once they are stale
+func (registry *currencyRegistry) load(ctx context.Context) (map[string]db.Currency, error) {
+	registry.mu.RLock()
+	byCode, loadedAt := registry.byCode, registry.loadedAt
+	registry.mu.RUnlock()
+	if byCode != nil && time.Since(loadedAt) < registry.ttl {
+		return byCode, nil
+	}
+
+	currencies, err := registry.store.ListCurrencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byCode = make(map[string]db.Currency, len(currencies))
+	for _, currency := range currencies {
+		byCode[currency.Code] = currency
+	}
+
+	registry.mu.Lock()
+	registry.byCode, registry.loadedAt = byCode, time.Now()
+	registry.mu.Unlock()
+	return byCode, nil
+}
+
+// set replaces a single cached currency after it was changed through this server
+func (registry *currencyRegistry) set(currency db.Currency) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if registry.byCode == nil {
+		return
+	}
+
+	// copy so that maps handed out by load are never written to
+	byCode := make(map[string]db.Currency, len(registry.byCode))
+	for code, c := range registry.byCode {
+		byCode[code] = c
+	}
+	byCode[currency.Code] = currency
+	registry.byCode = byCode
+}
+
+type currencyResponse struct {
+	Code        string `json:"code"`
+	NumericCode int32  `json:"numeric_code"`
+	Exponent    int16  `json:"exponent"`
+	Enabled     bool   `json:"enabled"`
+}
+
+func newCurrencyResponse(currency db.Currency) currencyResponse {
+	return currencyResponse{
+		Code:        currency.Code,
+		NumericCode: currency.NumericCode,
+		Exponent:    currency.Exponent,
+		Enabled:     currency.Enabled,
+	}
+}
+
+// listCurrencies returns the currencies accounts and transfers can use
+func (server *Server) listCurrencies(ctx *gin.Context) {
+	server.respondCurrencies(ctx, false)
+}
+
+// listAllCurrencies returns every known currency, including disabled ones
+func (server *Server) listAllCurrencies(ctx *gin.Context) {
+	server.respondCurrencies(ctx, true)
+}
+
+func (server *Server) respondCurrencies(ctx *gin.Context, includeDisabled bool) {
+	currencies, err := server.store.ListCurrencies(ctx)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	rsp := []currencyResponse{}
+	for _, currency := range currencies {
+		if currency.Enabled || includeDisabled {
+			rsp = append(rsp, newCurrencyResponse(currency))
+		}
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type updateCurrencyURI struct {
+	Code string `uri:"code" binding:"required,len=3,uppercase"`
+}
+
+type updateCurrencyRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// updateCurrency enables or disables a currency. Disabling a currency stops
+// new accounts, transfers and cash movements in it; existing accounts keep
+// their balance.
+func (server *Server) updateCurrency(ctx *gin.Context) {
+	var uri updateCurrencyURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req updateCurrencyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	currency, err := server.store.UpdateCurrencyEnabled(ctx, db.UpdateCurrencyEnabledParams{
+		Code:    uri.Code,
+		Enabled: *req.Enabled,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, http.StatusNotFound, fmt.Errorf("currency %s not found", uri.Code))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.currencies.set(currency)
+	slog.Info("currency updated", "code", currency.Code, "enabled", currency.Enabled)
+
+	ctx.JSON(http.StatusOK, newCurrencyResponse(currency))
+}
+
+// resolveAmount converts a request amount into minor units of the currency,
+// which must be enabled
+func (server *Server) resolveAmount(ctx *gin.Context, amount moneyAmount, code string) (int64, bool) {
+	currency, ok, err := server.currencies.get(ctx, code)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return 0, false
+	}
+	if !ok {
+		respondError(ctx, http.StatusBadRequest, fmt.Errorf("currency %s is not supported", code))
+		return 0, false
+	}
+
+	minor, err := amount.minorUnits(currency.Exponent)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return 0, false
+	}
+	if minor <= 0 {
+		respondError(ctx, http.StatusBadRequest, errNonPositiveAmount)
+		return 0, false
+	}
+	return minor, true
+}
@@ -0,0 +1,422 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gurukanth/simplebank/db/mock"
+	db "github.com/gurukanth/simplebank/db/sqlc"
+	"github.com/gurukanth/simplebank/token"
+	"github.com/gurukanth/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// testCurrencies mirrors the currencies seeded by the migrations
+var testCurrencies = []db.Currency{
+	{Code: "EUR", NumericCode: 978, Exponent: 2, Enabled: true},
+	{Code: "GBP", NumericCode: 826, Exponent: 2, Enabled: false},
+	{Code: "INR", NumericCode: 356, Exponent: 2, Enabled: true},
+	{Code: "JPY", NumericCode: 392, Exponent: 0, Enabled: false},
+	{Code: "KWD", NumericCode: 414, Exponent: 3, Enabled: false},
+	{Code: "USD", NumericCode: 840, Exponent: 2, Enabled: true},
+}
+
+// randomCurrency returns one of the enabled testCurrencies
+func randomCurrency() string {
+	var enabled []string
+	for _, currency := range testCurrencies {
+		if currency.Enabled {
+			enabled = append(enabled, currency.Code)
+		}
+	}
+	return enabled[util.RandomInt(0, int64(len(enabled)-1))]
+}
+
+// stubCurrencies lets the currency registry load testCurrencies
+func stubCurrencies(store *mockdb.MockStore) {
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		AnyTimes().
+		Return(testCurrencies, nil)
+}
+
+func TestListCurrenciesAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		Times(1).
+		Return(testCurrencies, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/currencies", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var rsp []currencyResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &rsp)
+	require.NoError(t, err)
+	require.Equal(t, []currencyResponse{
+		{Code: "EUR", NumericCode: 978, Exponent: 2, Enabled: true},
+		{Code: "INR", NumericCode: 356, Exponent: 2, Enabled: true},
+		{Code: "USD", NumericCode: 840, Exponent: 2, Enabled: true},
+	}, rsp)
+}
+
+func TestListAllCurrenciesAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListCurrencies(gomock.Any()).
+					Times(1).
+					Return(testCurrencies, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp []currencyResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.Len(t, rsp, len(testCurrencies))
+			},
+		},
+		{
+			name: "NotAdmin",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.CustomerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListCurrencies(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			stubActiveSession(store)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/admin/currencies", nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateCurrencyAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		code          string
+		body          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Enable",
+			code: "JPY",
+			body: `{"enabled": true}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCurrencyEnabled(gomock.Any(), gomock.Eq(db.UpdateCurrencyEnabledParams{Code: "JPY", Enabled: true})).
+					Times(1).
+					Return(db.Currency{Code: "JPY", NumericCode: 392, Exponent: 0, Enabled: true}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp currencyResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.Equal(t, currencyResponse{Code: "JPY", NumericCode: 392, Exponent: 0, Enabled: true}, rsp)
+			},
+		},
+		{
+			name: "Disable",
+			code: "EUR",
+			body: `{"enabled": false}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCurrencyEnabled(gomock.Any(), gomock.Eq(db.UpdateCurrencyEnabledParams{Code: "EUR", Enabled: false})).
+					Times(1).
+					Return(db.Currency{Code: "EUR", NumericCode: 978, Exponent: 2, Enabled: false}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "MissingEnabled",
+			code: "EUR",
+			body: `{}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCurrencyEnabled(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				rsp := requireBodyAPIError(t, recorder, codeValidationFailed)
+				require.Equal(t, "enabled", rsp.Fields[0].Field)
+			},
+		},
+		{
+			name: "InvalidCode",
+			code: "usd",
+			body: `{"enabled": true}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCurrencyEnabled(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			code: "XYZ",
+			body: `{"enabled": true}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCurrencyEnabled(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Currency{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "NotAdmin",
+			code: "JPY",
+			body: `{"enabled": true}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.TellerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCurrencyEnabled(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			stubActiveSession(store)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/admin/currencies/%s", tc.code)
+			request, err := http.NewRequest(http.MethodPatch, url, bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCurrencyRegistry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		Times(1).
+		Return(testCurrencies, nil)
+
+	registry := newCurrencyRegistry(store, time.Minute)
+	ctx := context.Background()
+
+	usd, ok, err := registry.get(ctx, "USD")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, int16(2), usd.Exponent)
+
+	// disabled and unknown currencies are not usable
+	_, ok, err = registry.get(ctx, "JPY")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, ok, err = registry.get(ctx, "XYZ")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// changes made through the server apply without reloading
+	registry.set(db.Currency{Code: "JPY", NumericCode: 392, Exponent: 0, Enabled: true})
+	_, ok, err = registry.get(ctx, "JPY")
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestCurrencyRegistryReload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().
+			ListCurrencies(gomock.Any()).
+			Times(1).
+			Return(testCurrencies, nil),
+		store.EXPECT().
+			ListCurrencies(gomock.Any()).
+			Times(1).
+			Return(nil, sql.ErrConnDone),
+	)
+
+	registry := newCurrencyRegistry(store, 0)
+	ctx := context.Background()
+
+	_, ok, err := registry.get(ctx, "USD")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, _, err = registry.get(ctx, "USD")
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
+
+func TestMoneyAmount(t *testing.T) {
+	testCases := []struct {
+		name     string
+		json     string
+		exponent int16
+		want     int64
+		wantErr  bool
+	}{
+		{name: "MinorUnits", json: `1050`, exponent: 2, want: 1050},
+		{name: "Decimal", json: `"10.50"`, exponent: 2, want: 1050},
+		{name: "DecimalWhole", json: `"10"`, exponent: 2, want: 1000},
+		{name: "DecimalOneDigit", json: `"10.5"`, exponent: 2, want: 1050},
+		{name: "DecimalTrailingZeros", json: `"10.500"`, exponent: 2, want: 1050},
+		{name: "ZeroExponent", json: `"1500"`, exponent: 0, want: 1500},
+		{name: "ThreeDigitExponent", json: `"1.234"`, exponent: 3, want: 1234},
+		{name: "TooManyDecimals", json: `"10.505"`, exponent: 2, wantErr: true},
+		{name: "FractionForZeroExponent", json: `"10.5"`, exponent: 0, wantErr: true},
+		{name: "OutOfRange", json: `"99999999999999999999"`, exponent: 2, wantErr: true},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			var amount moneyAmount
+			err := json.Unmarshal([]byte(tc.json), &amount)
+			require.NoError(t, err)
+
+			minor, err := amount.minorUnits(tc.exponent)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, minor)
+		})
+	}
+
+	for _, invalid := range []string{`10.5`, `"ten"`, `"1e3"`, `"10."`, `true`} {
+		var amount moneyAmount
+		require.Error(t, json.Unmarshal([]byte(invalid), &amount), invalid)
+	}
+}
+
+func TestCurrencyValidatorBoundsLookup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context) ([]db.Currency, error) {
+			deadline, ok := ctx.Deadline()
+			require.True(t, ok)
+			require.WithinDuration(t, time.Now().Add(currencyLookupTimeout), deadline, time.Second)
+			return testCurrencies, nil
+		})
+
+	// the servers share gin's validator, the latest one supplies the currencies
+	newTestServer(t, mockdb.NewMockStore(ctrl))
+	server := newTestServer(t, store)
+
+	request := struct {
+		Currency string `binding:"currency"`
+	}{Currency: "USD"}
+	require.NoError(t, binding.Validator.ValidateStruct(&request))
+	require.Same(t, server.currencies, validatorCurrencies.Load())
+
+	request.Currency = "JPY"
+	require.Error(t, binding.Validator.ValidateStruct(&request))
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"time"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// maxCursorTime sorts after every row, it starts pages ordered newest first
+var maxCursorTime = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// pageCursor is the position of a row in a keyset ordered list. Rows are
+// compared by (CreatedAt, ID), CreatedAt is left zero for lists ordered by ID.
+type pageCursor struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	// Before selects the page preceding the row instead of the one after it
+	Before bool `json:"before,omitempty"`
+}
+
+// firstPageCursor returns the cursor of a first page ordered newest first
+func firstPageCursor() pageCursor {
+	return pageCursor{ID: math.MaxInt64, CreatedAt: maxCursorTime}
+}
+
+// cursorCodec turns page cursors into opaque strings signed with HMAC-SHA256,
+// so that clients cannot forge positions
+type cursorCodec struct {
+	secret []byte
+}
+
+func newCursorCodec(secret string) (*cursorCodec, error) {
+	if secret == "" {
+		return nil, errors.New("cursor secret is required")
+	}
+	return &cursorCodec{secret: []byte(secret)}, nil
+}
+
+func (codec *cursorCodec) encode(cursor pageCursor) string {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		// pageCursor always marshals
+		panic(err)
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(codec.sign(payload))
+}
+
+func (codec *cursorCodec) decode(s string) (pageCursor, error) {
+	var cursor pageCursor
+
+	payload, signature, ok := strings.Cut(s, ".")
+	if !ok {
+		return cursor, errInvalidCursor
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, codec.sign(payload)) {
+		return cursor, errInvalidCursor
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return cursor, errInvalidCursor
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, errInvalidCursor
+	}
+	return cursor, nil
+}
+
+func (codec *cursorCodec) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, codec.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// cursorPage is the response envelope of cursor paginated lists
+type cursorPage[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// newCursorPage builds the page for rows fetched with limit+1 from the given
+// cursor, in display order. keyOf returns the position of a row.
+func newCursorPage[T any](codec *cursorCodec, rows []T, limit int, from pageCursor, started bool, keyOf func(T) pageCursor) cursorPage[T] {
+	hasMore := len(rows) > limit
+	if hasMore {
+		if from.Before {
+			rows = rows[len(rows)-limit:]
+		} else {
+			rows = rows[:limit]
+		}
+	}
+
+	page := cursorPage[T]{Items: rows}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if len(rows) == 0 {
+		return page
+	}
+
+	// a backward page always has rows after it, a forward page has rows
+	// before it unless it is the first one
+	if hasMore || from.Before {
+		next := keyOf(rows[len(rows)-1])
+		page.NextCursor = codec.encode(next)
+	}
+	if (hasMore && from.Before) || (!from.Before && started) {
+		prev := keyOf(rows[0])
+		prev.Before = true
+		page.PrevCursor = codec.encode(prev)
+	}
+	return page
+}
+
+// reverse reverses rows in place, backward pages are fetched in reverse order
+func reverse[T any](rows []T) {
+	for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+		rows[i], rows[j] = rows[j], rows[i]
+	}
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gurukanth/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorCodec(t *testing.T) {
+	codec, err := newCursorCodec(util.RandomString(32))
+	require.NoError(t, err)
+
+	cursor := pageCursor{
+		ID:        util.RandomInt(1, 1000),
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		Before:    true,
+	}
+
+	decoded, err := codec.decode(codec.encode(cursor))
+	require.NoError(t, err)
+	require.Equal(t, cursor.ID, decoded.ID)
+	require.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+	require.True(t, decoded.Before)
+}
+
+func TestCursorCodecRejectsForgedCursor(t *testing.T) {
+	codec, err := newCursorCodec(util.RandomString(32))
+	require.NoError(t, err)
+
+	other, err := newCursorCodec(util.RandomString(32))
+	require.NoError(t, err)
+
+	encoded := codec.encode(pageCursor{ID: 10})
+
+	for _, s := range []string{
+		"",
+		"not-a-cursor",
+		other.encode(pageCursor{ID: 10}),
+		codec.encode(pageCursor{ID: 10})[1:],
+		encoded + "x",
+	} {
+		_, err := codec.decode(s)
+		require.ErrorIs(t, err, errInvalidCursor, s)
+	}
+}
+
+func TestNewCursorPage(t *testing.T) {
+	codec, err := newCursorCodec(util.RandomString(32))
+	require.NoError(t, err)
+
+	keyOf := func(id int64) pageCursor {
+		return pageCursor{ID: id}
+	}
+	decode := func(s string) pageCursor {
+		cursor, err := codec.decode(s)
+		require.NoError(t, err)
+		return cursor
+	}
+
+	//first page with more rows
+	page := newCursorPage(codec, []int64{1, 2, 3, 4}, 3, pageCursor{}, false, keyOf)
+	require.Equal(t, []int64{1, 2, 3}, page.Items)
+	require.Empty(t, page.PrevCursor)
+	require.Equal(t, pageCursor{ID: 3}, decode(page.NextCursor))
+
+	//last page reached going forward
+	page = newCursorPage(codec, []int64{4, 5}, 3, pageCursor{ID: 3}, true, keyOf)
+	require.Equal(t, []int64{4, 5}, page.Items)
+	require.Empty(t, page.NextCursor)
+	require.Equal(t, pageCursor{ID: 4, Before: true}, decode(page.PrevCursor))
+
+	//going back with more rows before
+	page = newCursorPage(codec, []int64{1, 2, 3, 4}, 3, pageCursor{ID: 5, Before: true}, true, keyOf)
+	require.Equal(t, []int64{2, 3, 4}, page.Items)
+	require.Equal(t, pageCursor{ID: 2, Before: true}, decode(page.PrevCursor))
+	require.Equal(t, pageCursor{ID: 4}, decode(page.NextCursor))
+
+	//going back to the first page
+	page = newCursorPage(codec, []int64{1, 2}, 3, pageCursor{ID: 3, Before: true}, true, keyOf)
+	require.Equal(t, []int64{1, 2}, page.Items)
+	require.Empty(t, page.PrevCursor)
+	require.Equal(t, pageCursor{ID: 2}, decode(page.NextCursor))
+
+	//empty page
+	page = newCursorPage(codec, nil, 3, pageCursor{}, false, keyOf)
+	require.NotNil(t, page.Items)
+	require.Empty(t, page.NextCursor)
+	require.Empty(t, page.PrevCursor)
+}
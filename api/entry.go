@@ -0,0 +1,167 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gurukanth/simplebank/db/sqlc"
+	"github.com/gurukanth/simplebank/token"
+)
+
+var errInvalidTimeRange = errors.New("from must be before to")
+
+type listEntriesRequest struct {
+	From      time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To        time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Direction string    `form:"direction" binding:"omitempty,oneof=credit debit"`
+	PageId    int32     `form:"page_id" binding:"omitempty,min=1"`
+	PageSize  int32     `form:"page_size" binding:"required,min=5,max=100"`
+	Cursor    string    `form:"cursor"`
+}
+
+type entryResponse struct {
+	ID             int64     `json:"id"`
+	AccountID      int64     `json:"account_id"`
+	Amount         int64     `json:"amount"`
+	RunningBalance int64     `json:"running_balance"`
+	TransferID     *int64    `json:"transfer_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func newEntryResponse(row db.ListAccountStatementRow) entryResponse {
+	rsp := entryResponse{
+		ID:             row.ID,
+		AccountID:      row.AccountID,
+		Amount:         row.Amount,
+		RunningBalance: row.RunningBalance,
+		CreatedAt:      row.CreatedAt,
+	}
+	if row.TransferID.Valid {
+		transferID := row.TransferID.Int64
+		rsp.TransferID = &transferID
+	}
+	return rsp
+}
+
+// listEntries returns the statement of an account between from and to, with
+// the balance after each entry. Computing that balance reads every entry
+// since from, so a request without from reads the whole account history.
+func (server *Server) listEntries(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req listEntriesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if req.To.IsZero() {
+		req.To = time.Now()
+	}
+	if !req.From.Before(req.To) {
+		respondError(ctx, http.StatusBadRequest, errInvalidTimeRange)
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, http.StatusNotFound, err)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if account.Owner != authPayload.Username {
+		respondError(ctx, http.StatusForbidden, errAccountNotOwned)
+		return
+	}
+
+	if req.PageId == 0 {
+		server.listEntriesByCursor(ctx, account, req)
+		return
+	}
+
+	rows, err := server.store.ListAccountStatement(ctx, db.ListAccountStatementParams{
+		AccountID:   account.ID,
+		FromTime:    req.From,
+		ToTime:      req.To,
+		Direction:   req.Direction,
+		LimitCount:  req.PageSize,
+		OffsetCount: (req.PageId - 1) * req.PageSize,
+	})
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	entries := make([]entryResponse, len(rows))
+	for i, row := range rows {
+		entries[i] = newEntryResponse(row)
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
+
+func (server *Server) listEntriesByCursor(ctx *gin.Context, account db.Account, req listEntriesRequest) {
+	var cursor pageCursor
+	if req.Cursor != "" {
+		var err error
+		if cursor, err = server.cursors.decode(req.Cursor); err != nil {
+			respondError(ctx, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	var entries []entryResponse
+	if cursor.Before {
+		rows, err := server.store.ListAccountStatementBefore(ctx, db.ListAccountStatementBeforeParams{
+			AccountID:       account.ID,
+			FromTime:        req.From,
+			ToTime:          req.To,
+			Direction:       req.Direction,
+			BeforeCreatedAt: cursor.CreatedAt,
+			BeforeID:        cursor.ID,
+			LimitCount:      req.PageSize + 1,
+		})
+		if err != nil {
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		for _, row := range rows {
+			entries = append(entries, newEntryResponse(db.ListAccountStatementRow(row)))
+		}
+		reverse(entries)
+	} else {
+		rows, err := server.store.ListAccountStatementAfter(ctx, db.ListAccountStatementAfterParams{
+			AccountID:      account.ID,
+			FromTime:       req.From,
+			ToTime:         req.To,
+			Direction:      req.Direction,
+			AfterCreatedAt: cursor.CreatedAt,
+			AfterID:        cursor.ID,
+			LimitCount:     req.PageSize + 1,
+		})
+		if err != nil {
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		for _, row := range rows {
+			entries = append(entries, newEntryResponse(db.ListAccountStatementRow(row)))
+		}
+	}
+
+	page := newCursorPage(server.cursors, entries, int(req.PageSize), cursor, req.Cursor != "", func(entry entryResponse) pageCursor {
+		return pageCursor{ID: entry.ID, CreatedAt: entry.CreatedAt}
+	})
+	ctx.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,265 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	db "github.com/gurukanth/simplebank/db/sqlc"
+	"github.com/gurukanth/simplebank/token"
+	"github.com/lib/pq"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+	requestIDKey    = "request_id"
+)
+
+// Error codes are part of the API contract: clients switch on them, so an
+// existing code must never change meaning.
+const (
+	codeInvalidRequest       = "invalid_request"
+	codeValidationFailed     = "validation_failed"
+	codeUnauthenticated      = "unauthenticated"
+	codeForbidden            = "forbidden"
+	codeNotFound             = "not_found"
+	codeConflict             = "conflict"
+	codeUnprocessable        = "unprocessable"
+	codeInternal             = "internal"
+	codeAlreadyExists        = "already_exists"
+	codeReferenceNotFound    = "reference_not_found"
+	codeAccountNotOwned      = "account_not_owned"
+	codeTransferNotOwned     = "transfer_not_owned"
+	codeInsufficientFunds    = "insufficient_funds"
+	codeIdempotencyKeyReused = "idempotency_key_reused"
+	codeInvalidCursor        = "invalid_cursor"
+	codeTokenExpired         = "token_expired"
+	codeTokenInvalid         = "token_invalid"
+	codeSessionRevoked       = "session_revoked"
+	codeInvalidCredentials   = "invalid_credentials"
+	codeRateUnavailable      = "rate_unavailable"
+	codeQuoteUnavailable     = "quote_unavailable"
+	codeQuoteMismatch        = "quote_mismatch"
+	codeUnbalancedJournal    = "unbalanced_journal"
+	codeReconcileRunning     = "reconciliation_running"
+	codeScheduleNotOwned     = "scheduled_transfer_not_owned"
+)
+
+const (
+	pqUniqueViolation     = "23505"
+	pqForeignKeyViolation = "23503"
+)
+
+// apiError is the body of every error response
+type apiError struct {
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Fields    []fieldError `json:"fields,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// fieldError describes why a single request field was rejected
+type fieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// knownErrors gives errors that clients need to tell apart their own code.
+// A non-empty message replaces the error text, which is not fit for clients.
+var knownErrors = []struct {
+	err     error
+	code    string
+	message string
+}{
+	{sql.ErrNoRows, codeNotFound, "resource not found"},
+	{bcrypt.ErrMismatchedHashAndPassword, codeInvalidCredentials, "incorrect username or password"},
+	{errInvalidCredentials, codeInvalidCredentials, ""},
+	{errAccountNotOwned, codeAccountNotOwned, ""},
+	{errTransferNotOwned, codeTransferNotOwned, ""},
+	{db.ErrInsufficientFunds, codeInsufficientFunds, ""},
+	{db.ErrIdempotencyKeyReused, codeIdempotencyKeyReused, ""},
+	{errInvalidCursor, codeInvalidCursor, ""},
+	{token.ErrorExpiredToken, codeTokenExpired, ""},
+	{token.ErrorInvalidToken, codeTokenInvalid, ""},
+	{errRevokedSession, codeSessionRevoked, ""},
+	{errRateUnavailable, codeRateUnavailable, ""},
+	{db.ErrFxQuoteUnavailable, codeQuoteUnavailable, ""},
+	{db.ErrFxQuoteMismatch, codeQuoteMismatch, ""},
+	{db.ErrUnbalancedJournal, codeUnbalancedJournal, ""},
+	{db.ErrReconciliationRunning, codeReconcileRunning, ""},
+	{errScheduleNotOwned, codeScheduleNotOwned, ""},
+}
+
+// defaultCodes gives the code of errors that have no more specific one
+var defaultCodes = map[int]string{
+	http.StatusBadRequest:          codeInvalidRequest,
+	http.StatusUnauthorized:        codeUnauthenticated,
+	http.StatusForbidden:           codeForbidden,
+	http.StatusNotFound:            codeNotFound,
+	http.StatusConflict:            codeConflict,
+	http.StatusUnprocessableEntity: codeUnprocessable,
+}
+
+// respondError aborts the request with err rendered as an apiError.
+// Errors the handler could not classify (status 500) are checked for
+// database constraint violations; anything else is logged and hidden from
+// the client behind a generic message.
+func respondError(ctx *gin.Context, status int, err error) {
+	if status == http.StatusInternalServerError {
+		status = dbErrorStatus(err, status)
+	}
+
+	rsp := newAPIError(status, err)
+	rsp.RequestID = ctx.GetString(requestIDKey)
+
+	if status >= http.StatusInternalServerError {
+		slog.Error("request failed",
+			"request_id", rsp.RequestID,
+			"method", ctx.Request.Method,
+			"path", ctx.FullPath(),
+			"error", err,
+		)
+	}
+
+	ctx.AbortWithStatusJSON(status, rsp)
+}
+
+func newAPIError(status int, err error) apiError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return apiError{
+			Code:    codeValidationFailed,
+			Message: "request has invalid fields",
+			Fields:  newFieldErrors(validationErrs),
+		}
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case status == http.StatusConflict && pqErr.Code == pqUniqueViolation:
+			return apiError{Code: codeAlreadyExists, Message: "resource already exists"}
+		case status == http.StatusForbidden && pqErr.Code == pqForeignKeyViolation:
+			return apiError{Code: codeReferenceNotFound, Message: "referenced resource does not exist"}
+		}
+	}
+
+	if status >= http.StatusInternalServerError {
+		return apiError{Code: codeInternal, Message: "internal server error"}
+	}
+
+	for _, known := range knownErrors {
+		if errors.Is(err, known.err) {
+			message := known.message
+			if message == "" {
+				message = err.Error()
+			}
+			return apiError{Code: known.code, Message: message}
+		}
+	}
+
+	code, ok := defaultCodes[status]
+	if !ok {
+		code = codeInvalidRequest
+	}
+	return apiError{Code: code, Message: err.Error()}
+}
+
+// dbErrorStatus maps constraint violations reported by Postgres to the
+// status they deserve, and returns fallback for any other error
+func dbErrorStatus(err error, fallback int) int {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return fallback
+	}
+
+	switch pqErr.Code {
+	case pqUniqueViolation:
+		return http.StatusConflict
+	case pqForeignKeyViolation:
+		return http.StatusForbidden
+	default:
+		return fallback
+	}
+}
+
+func newFieldErrors(errs validator.ValidationErrors) []fieldError {
+	fields := make([]fieldError, len(errs))
+	for i, fe := range errs {
+		fields[i] = fieldError{
+			Field:   fe.Field(),
+			Message: fieldErrorMessage(fe),
+		}
+	}
+	return fields
+}
+
+func fieldErrorMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", strings.Join(strings.Fields(fe.Param()), ", "))
+	case "currency":
+		return "must be a supported currency"
+	case "email":
+		return "must be a valid email address"
+	case "alpha":
+		return "must contain only letters"
+	case "alphanum":
+		return "must contain only letters and digits"
+	case "uuid":
+		return "must be a valid UUID"
+	default:
+		return fmt.Sprintf("failed the %s check", fe.Tag())
+	}
+}
+
+// fieldName reports a struct field by the name clients send it as
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// requestIDMiddleware tags the request with the ID sent by the client in
+// X-Request-ID, or a new one, and echoes it in the response
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(requestIDHeader)
+		if requestID == "" || len(requestID) > 128 {
+			requestID = uuid.NewString()
+		}
+
+		ctx.Set(requestIDKey, requestID)
+		ctx.Header(requestIDHeader, requestID)
+		ctx.Next()
+	}
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gurukanth/simplebank/db/mock"
+	db "github.com/gurukanth/simplebank/db/sqlc"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRespondError(t *testing.T) {
+	testCases := []struct {
+		name       string
+		status     int
+		err        error
+		wantStatus int
+		wantCode   string
+		wantMsg    string
+	}{
+		{
+			name:       "KnownError",
+			status:     http.StatusUnprocessableEntity,
+			err:        db.ErrInsufficientFunds,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   codeInsufficientFunds,
+			wantMsg:    db.ErrInsufficientFunds.Error(),
+		},
+		{
+			name:       "WrappedKnownError",
+			status:     http.StatusUnauthorized,
+			err:        errors.Join(errors.New("lookup"), errAccountNotOwned),
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   codeAccountNotOwned,
+		},
+		{
+			name:       "DefaultCode",
+			status:     http.StatusBadRequest,
+			err:        errInvalidTimeRange,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   codeInvalidRequest,
+			wantMsg:    errInvalidTimeRange.Error(),
+		},
+		{
+			name:       "UniqueViolation",
+			status:     http.StatusInternalServerError,
+			err:        &pq.Error{Code: pqUniqueViolation, Message: "duplicate key value violates unique constraint"},
+			wantStatus: http.StatusConflict,
+			wantCode:   codeAlreadyExists,
+			wantMsg:    "resource already exists",
+		},
+		{
+			name:       "ForeignKeyViolation",
+			status:     http.StatusInternalServerError,
+			err:        &pq.Error{Code: pqForeignKeyViolation, Message: "insert or update violates foreign key constraint"},
+			wantStatus: http.StatusForbidden,
+			wantCode:   codeReferenceNotFound,
+		},
+		{
+			name:       "OtherDBError",
+			status:     http.StatusInternalServerError,
+			err:        &pq.Error{Code: "40001", Message: "could not serialize access"},
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   codeInternal,
+			wantMsg:    "internal server error",
+		},
+		{
+			name:       "InternalErrorHidden",
+			status:     http.StatusInternalServerError,
+			err:        errors.New("dial tcp 10.0.0.1:5432: connection refused"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   codeInternal,
+			wantMsg:    "internal server error",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			ctx.Set(requestIDKey, "req-1")
+
+			respondError(ctx, tc.status, tc.err)
+
+			require.Equal(t, tc.wantStatus, recorder.Code)
+			require.True(t, ctx.IsAborted())
+
+			rsp := requireBodyAPIError(t, recorder, tc.wantCode)
+			require.Equal(t, "req-1", rsp.RequestID)
+			if tc.wantMsg != "" {
+				require.Equal(t, tc.wantMsg, rsp.Message)
+			}
+		})
+	}
+}
+
+func TestValidationFieldErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	body := `{"username": "ab#", "email": "not-an-email", "password": "secret1"}`
+	request, err := http.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	rsp := requireBodyAPIError(t, recorder, codeValidationFailed)
+	require.ElementsMatch(t, []fieldError{
+		{Field: "username", Message: "must contain only letters and digits"},
+		{Field: "full_name", Message: "is required"},
+		{Field: "email", Message: "must be a valid email address"},
+	}, rsp.Fields)
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	// the client's ID is kept
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/accounts/1", nil)
+	require.NoError(t, err)
+	request.Header.Set(requestIDHeader, "client-id")
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	require.Equal(t, "client-id", recorder.Header().Get(requestIDHeader))
+
+	rsp := requireBodyAPIError(t, recorder, codeUnauthenticated)
+	require.Equal(t, "client-id", rsp.RequestID)
+
+	// a new ID is made up when the client sends none
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodGet, "/accounts/1", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.NotEmpty(t, recorder.Header().Get(requestIDHeader))
+	rsp = requireBodyAPIError(t, recorder, codeUnauthenticated)
+	require.Equal(t, recorder.Header().Get(requestIDHeader), rsp.RequestID)
+}
+
+func requireBodyAPIError(t *testing.T, recorder *httptest.ResponseRecorder, code string) apiError {
+	var rsp apiError
+	err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+	require.NoError(t, err)
+	require.Equal(t, code, rsp.Code)
+	require.NotEmpty(t, rsp.Message)
+	return rsp
+}
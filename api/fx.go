@@ -0,0 +1,186 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/gurukanth/simplebank/db/sqlc"
+	"github.com/gurukanth/simplebank/fx"
+	"github.com/gurukanth/simplebank/token"
+)
+
+var errRateUnavailable = errors.New("no exchange rate between these currencies")
+
+type exchangeRateResponse struct {
+	BaseCurrency  string    `json:"base_currency"`
+	QuoteCurrency string    `json:"quote_currency"`
+	Rate          string    `json:"rate"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func newExchangeRateResponse(rate db.ExchangeRate) exchangeRateResponse {
+	return exchangeRateResponse{
+		BaseCurrency:  rate.BaseCurrency,
+		QuoteCurrency: rate.QuoteCurrency,
+		Rate:          rate.Rate,
+		CreatedAt:     rate.CreatedAt,
+	}
+}
+
+// listExchangeRates returns the latest mid-market rate of every currency pair
+func (server *Server) listExchangeRates(ctx *gin.Context) {
+	rates, err := server.store.ListLatestExchangeRates(ctx)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	rsp := make([]exchangeRateResponse, len(rates))
+	for i, rate := range rates {
+		rsp[i] = newExchangeRateResponse(rate)
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type createExchangeRateRequest struct {
+	BaseCurrency  string `json:"base_currency" binding:"required,currency"`
+	QuoteCurrency string `json:"quote_currency" binding:"required,currency,nefield=BaseCurrency"`
+	Rate          string `json:"rate" binding:"required"`
+}
+
+// createExchangeRate records a new mid-market rate for a currency pair.
+// Rates are never updated in place, so past quotes can be audited.
+func (server *Server) createExchangeRate(ctx *gin.Context) {
+	var req createExchangeRateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	rate, err := fx.ParseRate(req.Rate)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	exchangeRate, err := server.store.CreateExchangeRate(ctx, db.CreateExchangeRateParams{
+		BaseCurrency:  req.BaseCurrency,
+		QuoteCurrency: req.QuoteCurrency,
+		Rate:          fx.FormatRate(rate),
+	})
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	slog.Info("exchange rate updated", "base", exchangeRate.BaseCurrency, "quote", exchangeRate.QuoteCurrency, "rate", exchangeRate.Rate)
+	ctx.JSON(http.StatusOK, newExchangeRateResponse(exchangeRate))
+}
+
+type createFxQuoteRequest struct {
+	FromCurrency string `json:"from_currency" binding:"required,currency"`
+	ToCurrency   string `json:"to_currency" binding:"required,currency,nefield=FromCurrency"`
+}
+
+type fxQuoteResponse struct {
+	ID           uuid.UUID `json:"id"`
+	FromCurrency string    `json:"from_currency"`
+	ToCurrency   string    `json:"to_currency"`
+	// Rate is what the customer gets, the mid-market rate less the spread
+	Rate      string    `json:"rate"`
+	MidRate   string    `json:"mid_rate"`
+	SpreadBps int32     `json:"spread_bps"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// createFxQuote locks the latest rate between two currencies for
+// config.FxQuoteTTL. The quote can be used by a single transfer of the user
+// it was given to.
+func (server *Server) createFxQuote(ctx *gin.Context) {
+	var req createFxQuoteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	rate, err := server.latestRate(ctx, req.FromCurrency, req.ToCurrency)
+	if err != nil {
+		if errors.Is(err, errRateUnavailable) {
+			respondError(ctx, http.StatusUnprocessableEntity, fmt.Errorf("%w: %s/%s", err, req.FromCurrency, req.ToCurrency))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	clientRate, err := fx.ApplySpread(rate, server.config.FxSpreadBps)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	quote, err := server.store.CreateFxQuote(ctx, db.CreateFxQuoteParams{
+		ID:           uuid.New(),
+		Username:     authPayload.Username,
+		FromCurrency: req.FromCurrency,
+		ToCurrency:   req.ToCurrency,
+		Rate:         fx.FormatRate(rate),
+		SpreadBps:    server.config.FxSpreadBps,
+		ExpiresAt:    time.Now().Add(server.config.FxQuoteTTL),
+	})
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, fxQuoteResponse{
+		ID:           quote.ID,
+		FromCurrency: quote.FromCurrency,
+		ToCurrency:   quote.ToCurrency,
+		Rate:         fx.FormatRate(clientRate),
+		MidRate:      quote.Rate,
+		SpreadBps:    quote.SpreadBps,
+		ExpiresAt:    quote.ExpiresAt,
+	})
+}
+
+// latestRate returns the latest mid-market rate from one currency to the
+// other, inverting the rate of the opposite direction when only that one
+// is known
+func (server *Server) latestRate(ctx *gin.Context, from, to string) (*big.Rat, error) {
+	exchangeRate, err := server.store.GetLatestExchangeRate(ctx, db.GetLatestExchangeRateParams{
+		BaseCurrency:  from,
+		QuoteCurrency: to,
+	})
+	if err == nil {
+		return fx.ParseRate(exchangeRate.Rate)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	exchangeRate, err = server.store.GetLatestExchangeRate(ctx, db.GetLatestExchangeRateParams{
+		BaseCurrency:  to,
+		QuoteCurrency: from,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errRateUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rate, err := fx.ParseRate(exchangeRate.Rate)
+	if err != nil {
+		return nil, err
+	}
+	return fx.Invert(rate), nil
+}
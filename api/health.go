@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gurukanth/simplebank/db/sqlc"
+	"github.com/gurukanth/simplebank/util"
+)
+
+// readinessTimeout bounds how long /readyz waits on the database
+const readinessTimeout = 2 * time.Second
+
+// healthz reports that the process is up and serving requests
+func (server *Server) healthz(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+type readinessCheck struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type readinessResponse struct {
+	Status string                    `json:"status"`
+	Checks map[string]readinessCheck `json:"checks"`
+}
+
+// readyz reports whether the server can take traffic: the database answers
+// within readinessTimeout and its schema is at db.SchemaVersion.
+func (server *Server) readyz(ctx *gin.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	rsp := readinessResponse{
+		Status: "ok",
+		Checks: map[string]readinessCheck{},
+	}
+	record := func(name string, err error) {
+		if err != nil {
+			rsp.Status = "unavailable"
+			rsp.Checks[name] = readinessCheck{Status: "fail", Error: err.Error()}
+			return
+		}
+		rsp.Checks[name] = readinessCheck{Status: "ok"}
+	}
+
+	err := server.store.Ping(checkCtx)
+	record("database", err)
+	if err == nil {
+		record("migrations", server.checkMigrations(checkCtx))
+	}
+
+	if rsp.Status != "ok" {
+		ctx.JSON(http.StatusServiceUnavailable, rsp)
+		return
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+func (server *Server) checkMigrations(ctx context.Context) error {
+	version, dirty, err := server.store.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", version)
+	}
+	if version != db.SchemaVersion {
+		return fmt.Errorf("schema version is %d, expected %d", version, db.SchemaVersion)
+	}
+	return nil
+}
+
+type versionResponse struct {
+	util.BuildInfo
+	SchemaVersion int64 `json:"schema_version"`
+}
+
+// version reports the build metadata and the schema version it expects
+func (server *Server) version(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, versionResponse{
+		BuildInfo:     util.GetBuildInfo(),
+		SchemaVersion: db.SchemaVersion,
+	})
+}
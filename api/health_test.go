@@ -0,0 +1,167 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gurukanth/simplebank/db/mock"
+	db "github.com/gurukanth/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthzAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().Ping(gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/healthz", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestReadyzAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					Ping(gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					MigrationVersion(gomock.Any()).
+					Times(1).
+					Return(int64(db.SchemaVersion), false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				rsp := requireBodyReadiness(t, recorder)
+				require.Equal(t, "ok", rsp.Status)
+				require.Equal(t, "ok", rsp.Checks["database"].Status)
+				require.Equal(t, "ok", rsp.Checks["migrations"].Status)
+			},
+		},
+		{
+			name: "DatabaseUnreachable",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					Ping(gomock.Any()).
+					Times(1).
+					Return(errors.New("connection refused"))
+				store.EXPECT().
+					MigrationVersion(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				rsp := requireBodyReadiness(t, recorder)
+				require.Equal(t, "unavailable", rsp.Status)
+				require.Equal(t, "fail", rsp.Checks["database"].Status)
+				require.Equal(t, "connection refused", rsp.Checks["database"].Error)
+			},
+		},
+		{
+			name: "SchemaBehind",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					Ping(gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					MigrationVersion(gomock.Any()).
+					Times(1).
+					Return(int64(db.SchemaVersion-1), false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				rsp := requireBodyReadiness(t, recorder)
+				require.Equal(t, "ok", rsp.Checks["database"].Status)
+				require.Equal(t, "fail", rsp.Checks["migrations"].Status)
+			},
+		},
+		{
+			name: "SchemaDirty",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					Ping(gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					MigrationVersion(gomock.Any()).
+					Times(1).
+					Return(int64(db.SchemaVersion), true, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				rsp := requireBodyReadiness(t, recorder)
+				require.Contains(t, rsp.Checks["migrations"].Error, "dirty")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestVersionAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/version", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var rsp versionResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &rsp)
+	require.NoError(t, err)
+	require.Equal(t, "dev", rsp.Version)
+	require.NotEmpty(t, rsp.GoVersion)
+	require.Equal(t, int64(db.SchemaVersion), rsp.SchemaVersion)
+}
+
+func requireBodyReadiness(t *testing.T, recorder *httptest.ResponseRecorder) readinessResponse {
+	var rsp readinessResponse
+	err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+	require.NoError(t, err)
+	return rsp
+}
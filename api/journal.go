@@ -0,0 +1,132 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gurukanth/simplebank/db/sqlc"
+)
+
+type postingRequest struct {
+	AccountID int64 `json:"account_id" binding:"required,min=1"`
+	// Amount is in minor units of the account currency, negative to take
+	// money out of the account
+	Amount int64 `json:"amount" binding:"required"`
+}
+
+type createJournalRequest struct {
+	Kind        string           `json:"kind" binding:"required,oneof=fee split adjustment"`
+	Description string           `json:"description" binding:"required,max=255"`
+	Postings    []postingRequest `json:"postings" binding:"required,min=2,dive"`
+}
+
+type journalEntryResponse struct {
+	ID        int64     `json:"id"`
+	AccountID int64     `json:"account_id"`
+	Amount    int64     `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type journalResponse struct {
+	ID          int64                  `json:"id"`
+	Kind        string                 `json:"kind"`
+	Description string                 `json:"description"`
+	TransferID  *int64                 `json:"transfer_id,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+	Entries     []journalEntryResponse `json:"entries"`
+}
+
+func newJournalResponse(journal db.JournalTransaction, entries []db.Entry) journalResponse {
+	rsp := journalResponse{
+		ID:          journal.ID,
+		Kind:        journal.Kind,
+		Description: journal.Description,
+		CreatedAt:   journal.CreatedAt,
+		Entries:     make([]journalEntryResponse, len(entries)),
+	}
+	if journal.TransferID.Valid {
+		transferID := journal.TransferID.Int64
+		rsp.TransferID = &transferID
+	}
+	for i, entry := range entries {
+		rsp.Entries[i] = journalEntryResponse{
+			ID:        entry.ID,
+			AccountID: entry.AccountID,
+			Amount:    entry.Amount,
+			CreatedAt: entry.CreatedAt,
+		}
+	}
+	return rsp
+}
+
+// createJournal posts a manual journal, such as a fee or a payment split
+// between several accounts. The postings must balance in each currency.
+func (server *Server) createJournal(ctx *gin.Context) {
+	var req createJournalRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	arg := db.PostJournalTxParams{
+		Kind:        req.Kind,
+		Description: req.Description,
+		Postings:    make([]db.Posting, len(req.Postings)),
+	}
+	for i, posting := range req.Postings {
+		arg.Postings[i] = db.Posting{
+			AccountID: posting.AccountID,
+			Amount:    posting.Amount,
+		}
+	}
+
+	result, err := server.store.PostJournalTx(ctx, arg)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrInvalidJournal):
+			respondError(ctx, http.StatusBadRequest, err)
+		case errors.Is(err, db.ErrUnbalancedJournal), errors.Is(err, db.ErrInsufficientFunds):
+			respondError(ctx, http.StatusUnprocessableEntity, err)
+		case errors.Is(err, sql.ErrNoRows):
+			respondError(ctx, http.StatusNotFound, err)
+		default:
+			respondError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newJournalResponse(result.Journal, result.Entries))
+}
+
+type getJournalRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getJournal(ctx *gin.Context) {
+	var req getJournalRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	journal, err := server.store.GetJournalTransaction(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, http.StatusNotFound, err)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	entries, err := server.store.ListJournalEntries(ctx, journal.ID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newJournalResponse(journal, entries))
+}
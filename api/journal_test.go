@@ -0,0 +1,290 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gurukanth/simplebank/db/mock"
+	db "github.com/gurukanth/simplebank/db/sqlc"
+	"github.com/gurukanth/simplebank/token"
+	"github.com/gurukanth/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateJournalAPI(t *testing.T) {
+	journal := db.JournalTransaction{
+		ID:          util.RandomInt(1, 1000),
+		Kind:        "fee",
+		Description: "monthly account fee",
+	}
+
+	testCases := []struct {
+		name          string
+		body          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: `{"kind": "fee", "description": "monthly account fee", "postings": [{"account_id": 1, "amount": -500}, {"account_id": 2, "amount": 500}]}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.PostJournalTxParams{
+					Kind:        "fee",
+					Description: "monthly account fee",
+					Postings: []db.Posting{
+						{AccountID: 1, Amount: -500},
+						{AccountID: 2, Amount: 500},
+					},
+				}
+				store.EXPECT().
+					PostJournalTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.PostJournalTxResult{
+						Journal: journal,
+						Entries: []db.Entry{
+							{ID: 1, AccountID: 1, Amount: -500, JournalID: journal.ID},
+							{ID: 2, AccountID: 2, Amount: 500, JournalID: journal.ID},
+						},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp journalResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.Equal(t, journal.ID, rsp.ID)
+				require.Nil(t, rsp.TransferID)
+				require.Len(t, rsp.Entries, 2)
+			},
+		},
+		{
+			name: "SinglePosting",
+			body: `{"kind": "fee", "description": "monthly account fee", "postings": [{"account_id": 1, "amount": -500}]}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PostJournalTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireBodyAPIError(t, recorder, codeValidationFailed)
+			},
+		},
+		{
+			name: "ReservedKind",
+			body: `{"kind": "transfer", "description": "manual transfer", "postings": [{"account_id": 1, "amount": -500}, {"account_id": 2, "amount": 500}]}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PostJournalTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				rsp := requireBodyAPIError(t, recorder, codeValidationFailed)
+				require.Equal(t, "kind", rsp.Fields[0].Field)
+			},
+		},
+		{
+			name: "Unbalanced",
+			body: `{"kind": "split", "description": "dinner", "postings": [{"account_id": 1, "amount": -500}, {"account_id": 2, "amount": 400}]}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PostJournalTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PostJournalTxResult{}, fmt.Errorf("%w: USD postings sum to -100", db.ErrUnbalancedJournal))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyAPIError(t, recorder, codeUnbalancedJournal)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			body: `{"kind": "fee", "description": "monthly account fee", "postings": [{"account_id": 1, "amount": -500}, {"account_id": 2, "amount": 500}]}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PostJournalTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PostJournalTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyAPIError(t, recorder, codeInsufficientFunds)
+			},
+		},
+		{
+			name: "AccountNotFound",
+			body: `{"kind": "fee", "description": "monthly account fee", "postings": [{"account_id": 1, "amount": -500}, {"account_id": 2, "amount": 500}]}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PostJournalTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PostJournalTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "NotAdmin",
+			body: `{"kind": "fee", "description": "monthly account fee", "postings": [{"account_id": 1, "amount": -500}, {"account_id": 2, "amount": 500}]}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.TellerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PostJournalTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			stubActiveSession(store)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/admin/journals", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetJournalAPI(t *testing.T) {
+	journal := db.JournalTransaction{
+		ID:         util.RandomInt(1, 1000),
+		Kind:       db.JournalKindTransfer,
+		TransferID: sql.NullInt64{Int64: util.RandomInt(1, 1000), Valid: true},
+	}
+	entries := []db.Entry{
+		{ID: 1, AccountID: 1, Amount: -10, JournalID: journal.ID},
+		{ID: 2, AccountID: 2, Amount: 10, JournalID: journal.ID},
+	}
+
+	testCases := []struct {
+		name          string
+		journalID     int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			journalID: journal.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetJournalTransaction(gomock.Any(), gomock.Eq(journal.ID)).
+					Times(1).
+					Return(journal, nil)
+				store.EXPECT().
+					ListJournalEntries(gomock.Any(), gomock.Eq(journal.ID)).
+					Times(1).
+					Return(entries, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp journalResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.Equal(t, journal.TransferID.Int64, *rsp.TransferID)
+				require.Equal(t, int64(-10), rsp.Entries[0].Amount)
+				require.Equal(t, int64(10), rsp.Entries[1].Amount)
+			},
+		},
+		{
+			name:      "NotFound",
+			journalID: journal.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetJournalTransaction(gomock.Any(), gomock.Eq(journal.ID)).
+					Times(1).
+					Return(db.JournalTransaction{}, sql.ErrNoRows)
+				store.EXPECT().
+					ListJournalEntries(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidID",
+			journalID: 0,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetJournalTransaction(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			stubActiveSession(store)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/admin/journals/%d", tc.journalID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.AdminRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
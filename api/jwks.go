@@ -0,0 +1,20 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gurukanth/simplebank/token"
+)
+
+// getJWKS publishes the public keys that downstream services use to verify
+// our tokens. Makers with symmetric keys publish an empty set.
+func (server *Server) getJWKS(ctx *gin.Context) {
+	keySet := token.JSONWebKeySet{Keys: []token.JSONWebKey{}}
+	if publisher, ok := server.tokenMaker.(token.KeySetPublisher); ok {
+		keySet = publisher.JWKS()
+	}
+
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, keySet)
+}
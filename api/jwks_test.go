@@ -0,0 +1,67 @@
+package api
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gurukanth/simplebank/token"
+	"github.com/gurukanth/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWKSAPI(t *testing.T) {
+	dir := t.TempDir()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "2026-01.pem"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+	require.NoError(t, err)
+
+	config := util.Config{
+		TokenType:           "jwt-asymmetric",
+		TokenKeyDir:         dir,
+		CursorSecret:        util.RandomString(32),
+		AccessTokenDuration: time.Minute,
+	}
+	server, err := NewServer(config, nil)
+	require.NoError(t, err)
+
+	keySet := requireJWKS(t, server)
+	require.Len(t, keySet.Keys, 1)
+	require.Equal(t, "2026-01", keySet.Keys[0].KeyID)
+	require.Equal(t, "EdDSA", keySet.Keys[0].Algorithm)
+}
+
+func TestJWKSAPISymmetricMaker(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	keySet := requireJWKS(t, server)
+	require.Empty(t, keySet.Keys)
+}
+
+func requireJWKS(t *testing.T, server *Server) token.JSONWebKeySet {
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var keySet token.JSONWebKeySet
+	err = json.Unmarshal(recorder.Body.Bytes(), &keySet)
+	require.NoError(t, err)
+	require.NotNil(t, keySet.Keys)
+
+	return keySet
+}
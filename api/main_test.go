@@ -3,10 +3,35 @@ package api
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	db "github.com/gurukanth/simplebank/db/sqlc"
+	"github.com/gurukanth/simplebank/util"
+	"github.com/stretchr/testify/require"
 )
 
+func newTestServer(t *testing.T, store db.Store) *Server {
+	config := util.Config{
+		TokenSymmetricKey:    util.RandomString(32),
+		CursorSecret:         util.RandomString(32),
+		AccessTokenDuration:  time.Minute,
+		RefreshTokenDuration: time.Hour,
+		SessionCacheTTL:      time.Minute,
+		CurrencyCacheTTL:     time.Minute,
+		FxQuoteTTL:           30 * time.Second,
+		FxSpreadBps:          50,
+		ReconcileSettleDelay: time.Minute,
+		ScheduledRetryDelay:  time.Hour,
+		ScheduledMaxFailures: 3,
+	}
+
+	server, err := NewServer(config, store)
+	require.NoError(t, err)
+
+	return server
+}
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 	os.Exit(m.Run())
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gurukanth/simplebank/token"
+)
+
+const (
+	authorizationHeaderKey  = "authorization"
+	authorizationTypeBearer = "bearer"
+	authorizationPayloadKey = "authorization_payload"
+)
+
+var (
+	errMissingAuthorization   = errors.New("authorization header is not provided")
+	errMalformedAuthorization = errors.New("invalid authorization header format")
+	errRevokedSession         = errors.New("session has been revoked")
+)
+
+// authMiddleware verifies the bearer token of the request, rejects tokens whose
+// session was revoked and stores the token payload in the context
+func authMiddleware(tokenMaker token.Maker, sessions *sessionCache) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
+		if len(authorizationHeader) == 0 {
+			respondError(ctx, http.StatusUnauthorized, errMissingAuthorization)
+			return
+		}
+
+		fields := strings.Fields(authorizationHeader)
+		if len(fields) != 2 {
+			respondError(ctx, http.StatusUnauthorized, errMalformedAuthorization)
+			return
+		}
+
+		authorizationType := strings.ToLower(fields[0])
+		if authorizationType != authorizationTypeBearer {
+			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
+			respondError(ctx, http.StatusUnauthorized, err)
+			return
+		}
+
+		payload, err := tokenMaker.VerifyToken(fields[1])
+		if err != nil {
+			if errors.Is(err, token.ErrorExpiredToken) {
+				respondError(ctx, http.StatusUnauthorized, token.ErrorExpiredToken)
+				return
+			}
+			respondError(ctx, http.StatusUnauthorized, token.ErrorInvalidToken)
+			return
+		}
+		// refresh tokens live much longer and may only renew access tokens
+		if payload.Type != token.AccessToken {
+			respondError(ctx, http.StatusUnauthorized, token.ErrorInvalidToken)
+			return
+		}
+
+		active, err := sessions.isActive(ctx, payload.SessionID)
+		if err != nil {
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		if !active {
+			respondError(ctx, http.StatusUnauthorized, errRevokedSession)
+			return
+		}
+
+		ctx.Set(authorizationPayloadKey, payload)
+		ctx.Next()
+	}
+}
+
+// requireScopes rejects requests whose token does not grant every scope.
+// It must run after authMiddleware.
+func requireScopes(scopes ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		for _, scope := range scopes {
+			if !authPayload.HasScope(scope) {
+				err := fmt.Errorf("missing required scope %s", scope)
+				respondError(ctx, http.StatusForbidden, err)
+				return
+			}
+		}
+
+		ctx.Next()
+	}
+}
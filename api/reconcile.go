@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gurukanth/simplebank/db/sqlc"
+)
+
+type reconciliationResponse struct {
+	ID                 int64                     `json:"id"`
+	Status             string                    `json:"status"`
+	EntryCheckpoint    int64                     `json:"entry_checkpoint"`
+	TransferCheckpoint int64                     `json:"transfer_checkpoint"`
+	AccountsChecked    int32                     `json:"accounts_checked"`
+	MismatchedAccounts int32                     `json:"mismatched_accounts"`
+	OrphanEntries      int32                     `json:"orphan_entries"`
+	OrphanTransfers    int32                     `json:"orphan_transfers"`
+	Details            *db.ReconciliationDetails `json:"details"`
+	StartedAt          time.Time                 `json:"started_at"`
+	FinishedAt         time.Time                 `json:"finished_at"`
+}
+
+func newReconciliationResponse(run db.ReconciliationRun) (reconciliationResponse, error) {
+	rsp := reconciliationResponse{
+		ID:                 run.ID,
+		Status:             run.Status,
+		EntryCheckpoint:    run.EntryCheckpoint,
+		TransferCheckpoint: run.TransferCheckpoint,
+		AccountsChecked:    run.AccountsChecked,
+		MismatchedAccounts: run.MismatchedAccounts,
+		OrphanEntries:      run.OrphanEntries,
+		OrphanTransfers:    run.OrphanTransfers,
+		StartedAt:          run.StartedAt,
+		FinishedAt:         run.FinishedAt,
+	}
+	if len(run.Details) > 0 {
+		rsp.Details = &db.ReconciliationDetails{}
+		if err := json.Unmarshal(run.Details, rsp.Details); err != nil {
+			return reconciliationResponse{}, err
+		}
+	}
+	return rsp, nil
+}
+
+// getReconciliation returns the latest reconciliation run
+func (server *Server) getReconciliation(ctx *gin.Context) {
+	run, err := server.store.GetLatestReconciliationRun(ctx)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, sql.ErrNoRows) {
+			status = http.StatusNotFound
+		}
+		respondError(ctx, status, err)
+		return
+	}
+
+	rsp, err := newReconciliationResponse(run)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// runReconciliation reconciles the ledger now instead of waiting for the
+// next scheduled run
+func (server *Server) runReconciliation(ctx *gin.Context) {
+	run, err := server.store.ReconcileTx(ctx, db.ReconcileTxParams{
+		SettleDelay: server.config.ReconcileSettleDelay,
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, db.ErrReconciliationRunning) {
+			status = http.StatusConflict
+		}
+		respondError(ctx, status, err)
+		return
+	}
+
+	rsp, err := newReconciliationResponse(run)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// runReconciler reconciles the ledger every interval until ctx is done.
+// Every instance of the server runs it; the reconciliation lock keeps their
+// runs from overlapping.
+func (server *Server) runReconciler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			server.reconcile(ctx)
+		}
+	}
+}
+
+func (server *Server) reconcile(ctx context.Context) {
+	run, err := server.store.ReconcileTx(ctx, db.ReconcileTxParams{
+		SettleDelay: server.config.ReconcileSettleDelay,
+	})
+	switch {
+	case errors.Is(err, db.ErrReconciliationRunning):
+		slog.Info("reconciliation skipped", "reason", err)
+	case err != nil:
+		if ctx.Err() == nil {
+			slog.Error("reconciliation failed", "error", err)
+		}
+	case run.Status != db.ReconciliationOK:
+		slog.Warn("ledger drift found",
+			"run_id", run.ID,
+			"mismatched_accounts", run.MismatchedAccounts,
+			"orphan_entries", run.OrphanEntries,
+			"orphan_transfers", run.OrphanTransfers,
+		)
+	default:
+		slog.Info("ledger reconciled",
+			"run_id", run.ID,
+			"accounts_checked", run.AccountsChecked,
+			"entry_checkpoint", run.EntryCheckpoint,
+		)
+	}
+}
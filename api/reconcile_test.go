@@ -0,0 +1,242 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gurukanth/simplebank/db/mock"
+	db "github.com/gurukanth/simplebank/db/sqlc"
+	"github.com/gurukanth/simplebank/token"
+	"github.com/gurukanth/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomReconciliationRun(t *testing.T, status string) db.ReconciliationRun {
+	details := db.ReconciliationDetails{
+		Mismatches:        []db.BalanceMismatch{},
+		OrphanEntryIDs:    []int64{},
+		OrphanTransferIDs: []int64{},
+	}
+	run := db.ReconciliationRun{
+		ID:                 util.RandomInt(1, 1000),
+		Status:             status,
+		EntryCheckpoint:    util.RandomInt(1, 1000),
+		TransferCheckpoint: util.RandomInt(1, 1000),
+		AccountsChecked:    int32(util.RandomInt(1, 100)),
+	}
+	if status == db.ReconciliationDrift {
+		details.Mismatches = append(details.Mismatches, db.BalanceMismatch{AccountID: 1, Balance: 100, EntrySum: 90})
+		run.MismatchedAccounts = 1
+	}
+
+	var err error
+	run.Details, err = json.Marshal(details)
+	require.NoError(t, err)
+	return run
+}
+
+func TestGetReconciliationAPI(t *testing.T) {
+	run := randomReconciliationRun(t, db.ReconciliationDrift)
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLatestReconciliationRun(gomock.Any()).
+					Times(1).
+					Return(run, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp reconciliationResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.Equal(t, run.ID, rsp.ID)
+				require.Equal(t, db.ReconciliationDrift, rsp.Status)
+				require.Equal(t, int32(1), rsp.MismatchedAccounts)
+				require.Equal(t, []db.BalanceMismatch{{AccountID: 1, Balance: 100, EntrySum: 90}}, rsp.Details.Mismatches)
+			},
+		},
+		{
+			name: "NoRuns",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLatestReconciliationRun(gomock.Any()).
+					Times(1).
+					Return(db.ReconciliationRun{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireBodyAPIError(t, recorder, codeNotFound)
+			},
+		},
+		{
+			name: "NotAdmin",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.TellerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLatestReconciliationRun(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			stubActiveSession(store)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/admin/reconciliation", nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRunReconciliationAPI(t *testing.T) {
+	run := randomReconciliationRun(t, db.ReconciliationOK)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ReconcileTxParams{SettleDelay: time.Minute}
+				store.EXPECT().
+					ReconcileTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(run, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp reconciliationResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.Equal(t, run.ID, rsp.ID)
+				require.Equal(t, db.ReconciliationOK, rsp.Status)
+				require.Empty(t, rsp.Details.Mismatches)
+			},
+		},
+		{
+			name: "AlreadyRunning",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReconcileTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReconciliationRun{}, db.ErrReconciliationRunning)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireBodyAPIError(t, recorder, codeReconcileRunning)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReconcileTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReconciliationRun{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			stubActiveSession(store)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/admin/reconciliation", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.AdminRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestServeRunsReconciler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ran := make(chan struct{})
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().
+			ReconcileTx(gomock.Any(), gomock.Any()).
+			MinTimes(1).
+			DoAndReturn(func(ctx context.Context, _ db.ReconcileTxParams) (db.ReconciliationRun, error) {
+				select {
+				case <-ran:
+				default:
+					close(ran)
+				}
+				return db.ReconciliationRun{}, errors.New("db down")
+			}),
+		store.EXPECT().Close().Times(1).Return(nil),
+	)
+
+	server := newTestServer(t, store)
+	server.config.ShutdownTimeout = time.Second
+	server.config.ReconcileInterval = 10 * time.Millisecond
+
+	_, cancel, done := startTestServe(t, server)
+	<-ran
+	cancel()
+
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,304 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gurukanth/simplebank/db/sqlc"
+	"github.com/gurukanth/simplebank/schedule"
+	"github.com/gurukanth/simplebank/token"
+)
+
+var (
+	errScheduleNotOwned   = errors.New("scheduled transfer doesn't belong to the authenticated user")
+	errScheduleFinished   = errors.New("scheduled transfer has already completed or been cancelled")
+	errStartNotInFuture   = errors.New("start_at must be in the future")
+	errEndBeforeStart     = errors.New("end_at must be after start_at")
+	errOnceWithRecurrence = errors.New("end_at and max_runs only apply to daily, weekly and monthly transfers")
+)
+
+type createScheduledTransferRequest struct {
+	FromAccountID int64       `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64       `json:"to_account_id" binding:"required,min=1"`
+	Amount        moneyAmount `json:"amount"`
+	Currency      string      `json:"currency" binding:"required,currency"`
+	// Frequency defaults to a single transfer at StartAt
+	Frequency string    `json:"frequency" binding:"omitempty,oneof=once daily weekly monthly"`
+	StartAt   time.Time `json:"start_at" binding:"required"`
+	// EndAt and MaxRuns end a standing order, whichever comes first
+	EndAt   *time.Time `json:"end_at"`
+	MaxRuns *int32     `json:"max_runs" binding:"omitempty,min=1"`
+}
+
+type scheduledTransferResponse struct {
+	ID            int64      `json:"id"`
+	FromAccountID int64      `json:"from_account_id"`
+	ToAccountID   int64      `json:"to_account_id"`
+	Amount        int64      `json:"amount"`
+	Frequency     string     `json:"frequency"`
+	StartAt       time.Time  `json:"start_at"`
+	EndAt         *time.Time `json:"end_at,omitempty"`
+	MaxRuns       *int32     `json:"max_runs,omitempty"`
+	Status        string     `json:"status"`
+	NextRunAt     *time.Time `json:"next_run_at,omitempty"`
+	RunsDone      int32      `json:"runs_done"`
+	FailureCount  int32      `json:"failure_count"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func newScheduledTransferResponse(scheduled db.ScheduledTransfer) scheduledTransferResponse {
+	rsp := scheduledTransferResponse{
+		ID:            scheduled.ID,
+		FromAccountID: scheduled.FromAccountID,
+		ToAccountID:   scheduled.ToAccountID,
+		Amount:        scheduled.Amount,
+		Frequency:     scheduled.Frequency,
+		StartAt:       scheduled.StartAt,
+		Status:        scheduled.Status,
+		RunsDone:      scheduled.RunsDone,
+		FailureCount:  scheduled.FailureCount,
+		CreatedAt:     scheduled.CreatedAt,
+	}
+	if scheduled.EndAt.Valid {
+		endAt := scheduled.EndAt.Time
+		rsp.EndAt = &endAt
+	}
+	if scheduled.MaxRuns.Valid {
+		maxRuns := scheduled.MaxRuns.Int32
+		rsp.MaxRuns = &maxRuns
+	}
+	// a finished transfer has nothing left to run
+	if scheduled.Status == db.ScheduleActive || scheduled.Status == db.SchedulePaused {
+		nextRunAt := scheduled.NextRunAt
+		rsp.NextRunAt = &nextRunAt
+	}
+	return rsp
+}
+
+// createScheduledTransfer schedules a transfer for a future date, or sets up
+// a standing order repeating it daily, weekly or monthly
+func (server *Server) createScheduledTransfer(ctx *gin.Context) {
+	var req createScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if req.Frequency == "" {
+		req.Frequency = schedule.Once
+	}
+	if !req.StartAt.After(time.Now()) {
+		respondError(ctx, http.StatusBadRequest, errStartNotInFuture)
+		return
+	}
+	if req.Frequency == schedule.Once && (req.EndAt != nil || req.MaxRuns != nil) {
+		respondError(ctx, http.StatusBadRequest, errOnceWithRecurrence)
+		return
+	}
+	if req.EndAt != nil && !req.EndAt.After(req.StartAt) {
+		respondError(ctx, http.StatusBadRequest, errEndBeforeStart)
+		return
+	}
+
+	amount, valid := server.resolveAmount(ctx, req.Amount, req.Currency)
+	if !valid {
+		return
+	}
+
+	fromAccount, valid := server.validateAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if fromAccount.Owner != authPayload.Username {
+		respondError(ctx, http.StatusForbidden, errAccountNotOwned)
+		return
+	}
+
+	if _, valid = server.validateAccount(ctx, req.ToAccountID, req.Currency); !valid {
+		return
+	}
+
+	arg := db.CreateScheduledTransferParams{
+		Owner:         authPayload.Username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        amount,
+		Frequency:     req.Frequency,
+		StartAt:       req.StartAt,
+	}
+	if req.EndAt != nil {
+		arg.EndAt = sql.NullTime{Time: *req.EndAt, Valid: true}
+	}
+	if req.MaxRuns != nil {
+		arg.MaxRuns = sql.NullInt32{Int32: *req.MaxRuns, Valid: true}
+	}
+
+	scheduled, err := server.store.CreateScheduledTransfer(ctx, arg)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newScheduledTransferResponse(scheduled))
+}
+
+type listScheduledTransfersRequest struct {
+	PageId   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=100"`
+}
+
+func (server *Server) listScheduledTransfers(ctx *gin.Context) {
+	var req listScheduledTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	scheduled, err := server.store.ListScheduledTransfers(ctx, db.ListScheduledTransfersParams{
+		Owner:  authPayload.Username,
+		Limit:  req.PageSize,
+		Offset: (req.PageId - 1) * req.PageSize,
+	})
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	rsp := make([]scheduledTransferResponse, len(scheduled))
+	for i := range scheduled {
+		rsp[i] = newScheduledTransferResponse(scheduled[i])
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type getScheduledTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type updateScheduledTransferRequest struct {
+	Status string `json:"status" binding:"required,oneof=active paused cancelled"`
+}
+
+// updateScheduledTransfer pauses, resumes or cancels a scheduled transfer.
+// A resumed transfer starts over with no failures, and runs its pending
+// occurrence straight away if that already fell due.
+func (server *Server) updateScheduledTransfer(ctx *gin.Context) {
+	var uri getScheduledTransferRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req updateScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, valid := server.validateScheduledTransfer(ctx, uri.ID); !valid {
+		return
+	}
+
+	scheduled, err := server.store.UpdateScheduledTransferStatus(ctx, db.UpdateScheduledTransferStatusParams{
+		ID:     uri.ID,
+		Status: req.Status,
+	})
+	if err != nil {
+		// only active and paused transfers are updated
+		if errors.Is(err, sql.ErrNoRows) {
+			respondError(ctx, http.StatusConflict, errScheduleFinished)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newScheduledTransferResponse(scheduled))
+}
+
+type listScheduledTransferRunsRequest struct {
+	PageId   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=100"`
+}
+
+type scheduledTransferRunResponse struct {
+	ID         int64     `json:"id"`
+	Occurrence int32     `json:"occurrence"`
+	Status     string    `json:"status"`
+	TransferID *int64    `json:"transfer_id,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// listScheduledTransferRuns lists the attempts to run a scheduled transfer,
+// most recent first
+func (server *Server) listScheduledTransferRuns(ctx *gin.Context) {
+	var uri getScheduledTransferRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req listScheduledTransferRunsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, valid := server.validateScheduledTransfer(ctx, uri.ID); !valid {
+		return
+	}
+
+	runs, err := server.store.ListScheduledTransferRuns(ctx, db.ListScheduledTransferRunsParams{
+		ScheduledTransferID: uri.ID,
+		Limit:               req.PageSize,
+		Offset:              (req.PageId - 1) * req.PageSize,
+	})
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	rsp := make([]scheduledTransferRunResponse, len(runs))
+	for i, run := range runs {
+		rsp[i] = scheduledTransferRunResponse{
+			ID:         run.ID,
+			Occurrence: run.Occurrence,
+			Status:     run.Status,
+			Error:      run.Error,
+			CreatedAt:  run.CreatedAt,
+		}
+		if run.TransferID.Valid {
+			transferID := run.TransferID.Int64
+			rsp[i].TransferID = &transferID
+		}
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// validateScheduledTransfer loads a scheduled transfer and checks that it
+// belongs to the authenticated user
+func (server *Server) validateScheduledTransfer(ctx *gin.Context, id int64) (db.ScheduledTransfer, bool) {
+	scheduled, err := server.store.GetScheduledTransfer(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondError(ctx, http.StatusNotFound, err)
+			return scheduled, false
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return scheduled, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if scheduled.Owner != authPayload.Username {
+		respondError(ctx, http.StatusForbidden, errScheduleNotOwned)
+		return scheduled, false
+	}
+	return scheduled, true
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	db "github.com/gurukanth/simplebank/db/sqlc"
+)
+
+const (
+	// scheduledTransferBatch is how many due transfers a scheduler claims at
+	// a time
+	scheduledTransferBatch = 100
+	// scheduledTransferLease is how long a claimed occurrence is left to the
+	// scheduler that claimed it before another one may run it
+	scheduledTransferLease = 5 * time.Minute
+	// scheduledTransferKeyPrefix starts the idempotency keys of scheduled
+	// transfers. Clients may not send keys with it, so that they cannot
+	// collide with an occurrence.
+	scheduledTransferKeyPrefix = "scheduled-transfer:"
+)
+
+// runScheduler runs the scheduled transfers that fall due, checking every
+// interval until ctx is done. Every instance of the server runs it; claimed
+// rows are skipped by the others.
+func (server *Server) runScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			server.runDueTransfers(ctx)
+		}
+	}
+}
+
+// runDueTransfers claims due scheduled transfers in batches and runs them
+// until none is left
+func (server *Server) runDueTransfers(ctx context.Context) {
+	for ctx.Err() == nil {
+		due, err := server.store.ClaimDueScheduledTransfers(ctx, db.ClaimDueScheduledTransfersParams{
+			LeaseSeconds: int32(scheduledTransferLease / time.Second),
+			BatchSize:    scheduledTransferBatch,
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("cannot claim scheduled transfers", "error", err)
+			}
+			return
+		}
+
+		for _, scheduled := range due {
+			server.runScheduledTransfer(ctx, scheduled)
+		}
+		if len(due) < scheduledTransferBatch {
+			return
+		}
+	}
+}
+
+// runScheduledTransfer makes the transfer of the pending occurrence of
+// scheduled and records how it went. The transfer is keyed by the
+// occurrence, so running it again after a crash replays the first transfer
+// instead of moving the money twice.
+func (server *Server) runScheduledTransfer(ctx context.Context, scheduled db.ScheduledTransfer) {
+	key := fmt.Sprintf("%s%d:%d", scheduledTransferKeyPrefix, scheduled.ID, scheduled.Occurrence)
+	result, err := server.store.TransferTx(ctx, db.TransferTxParams{
+		FromAccountId:  scheduled.FromAccountID,
+		ToAccountId:    scheduled.ToAccountID,
+		Amount:         scheduled.Amount,
+		IdempotencyKey: key,
+		Username:       scheduled.Owner,
+		RequestHash:    key,
+	})
+	if ctx.Err() != nil {
+		// shutting down: the claim lapses and the occurrence is run again
+		return
+	}
+
+	arg := db.RecordScheduledRunTxParams{
+		ScheduledTransferID: scheduled.ID,
+		Occurrence:          scheduled.Occurrence,
+		Err:                 err,
+		RetryDelay:          server.config.ScheduledRetryDelay,
+		MaxFailures:         server.config.ScheduledMaxFailures,
+	}
+	if err == nil {
+		arg.TransferID = result.Transfer.ID
+	}
+
+	recorded, recordErr := server.store.RecordScheduledRunTx(ctx, arg)
+	switch {
+	case errors.Is(recordErr, db.ErrStaleScheduledRun):
+		slog.Info("scheduled transfer run superseded", "scheduled_transfer_id", scheduled.ID, "occurrence", scheduled.Occurrence)
+	case recordErr != nil:
+		slog.Error("cannot record scheduled transfer run", "scheduled_transfer_id", scheduled.ID, "error", recordErr)
+	case err != nil:
+		slog.Warn("scheduled transfer failed",
+			"scheduled_transfer_id", scheduled.ID,
+			"occurrence", scheduled.Occurrence,
+			"failures", recorded.ScheduledTransfer.FailureCount,
+			"status", recorded.ScheduledTransfer.Status,
+			"error", err,
+		)
+	default:
+		slog.Info("scheduled transfer made",
+			"scheduled_transfer_id", scheduled.ID,
+			"occurrence", scheduled.Occurrence,
+			"transfer_id", result.Transfer.ID,
+		)
+	}
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gurukanth/simplebank/db/mock"
+	db "github.com/gurukanth/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunDueTransfers(t *testing.T) {
+	account1 := randomAccount()
+	account2 := randomAccount()
+	scheduled := randomScheduledTransfer(account1.Owner, account1, account2)
+	scheduled.Occurrence = 4
+	key := fmt.Sprintf("scheduled-transfer:%d:4", scheduled.ID)
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name: "Succeeded",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
+						FromAccountId:  scheduled.FromAccountID,
+						ToAccountId:    scheduled.ToAccountID,
+						Amount:         scheduled.Amount,
+						IdempotencyKey: key,
+						Username:       scheduled.Owner,
+						RequestHash:    key,
+					})).
+					Times(1).
+					Return(db.TransferTxResult{Transfer: db.Transfer{ID: 42}}, nil)
+
+				arg := db.RecordScheduledRunTxParams{
+					ScheduledTransferID: scheduled.ID,
+					Occurrence:          4,
+					TransferID:          42,
+					RetryDelay:          time.Hour,
+					MaxFailures:         3,
+				}
+				store.EXPECT().
+					RecordScheduledRunTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+
+				arg := db.RecordScheduledRunTxParams{
+					ScheduledTransferID: scheduled.ID,
+					Occurrence:          4,
+					Err:                 db.ErrInsufficientFunds,
+					RetryDelay:          time.Hour,
+					MaxFailures:         3,
+				}
+				store.EXPECT().
+					RecordScheduledRunTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1)
+			},
+		},
+		{
+			name: "Superseded",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1)
+				store.EXPECT().
+					RecordScheduledRunTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RecordScheduledRunTxResult{}, db.ErrStaleScheduledRun)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			arg := db.ClaimDueScheduledTransfersParams{
+				LeaseSeconds: int32(scheduledTransferLease / time.Second),
+				BatchSize:    scheduledTransferBatch,
+			}
+			store.EXPECT().
+				ClaimDueScheduledTransfers(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return([]db.ScheduledTransfer{scheduled}, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.runDueTransfers(context.Background())
+		})
+	}
+}
+
+func TestRunDueTransfersInBatches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	full := make([]db.ScheduledTransfer, scheduledTransferBatch)
+	for i := range full {
+		full[i] = db.ScheduledTransfer{ID: int64(i + 1)}
+	}
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().ClaimDueScheduledTransfers(gomock.Any(), gomock.Any()).Times(1).Return(full, nil),
+		store.EXPECT().ClaimDueScheduledTransfers(gomock.Any(), gomock.Any()).Times(1).Return(nil, nil),
+	)
+	store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(scheduledTransferBatch)
+	store.EXPECT().RecordScheduledRunTx(gomock.Any(), gomock.Any()).Times(scheduledTransferBatch)
+
+	server := newTestServer(t, store)
+	server.runDueTransfers(context.Background())
+}
+
+func TestRunScheduledTransferShuttingDown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		TransferTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, _ db.TransferTxParams) (db.TransferTxResult, error) {
+			cancel()
+			return db.TransferTxResult{}, ctx.Err()
+		})
+	// the claim is left to lapse so that another scheduler runs it again
+	store.EXPECT().RecordScheduledRunTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	server.runScheduledTransfer(ctx, db.ScheduledTransfer{ID: 1})
+	require.Error(t, ctx.Err())
+}
@@ -1,36 +1,188 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
 	"github.com/gin-gonic/gin"
 	db "github.com/gurukanth/simplebank/db/sqlc"
+	"github.com/gurukanth/simplebank/token"
+	"github.com/gurukanth/simplebank/util"
 )
 
 type Server struct {
-	store  db.Store
-	router *gin.Engine
+	config     util.Config
+	store      db.Store
+	tokenMaker token.Maker
+	sessions   *sessionCache
+	cursors    *cursorCodec
+	currencies *currencyRegistry
+	router     *gin.Engine
 }
 
-func NewServer(store db.Store) (server *Server) {
-	server = &Server{store: store}
+func NewServer(config util.Config, store db.Store) (*Server, error) {
+	tokenMaker, err := newTokenMaker(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	cursors, err := newCursorCodec(config.CursorSecret)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create cursor codec: %w", err)
+	}
+
+	server := &Server{
+		config:     config,
+		store:      store,
+		tokenMaker: tokenMaker,
+		sessions:   newSessionCache(store, config.SessionCacheTTL),
+		cursors:    cursors,
+		currencies: newCurrencyRegistry(store, config.CurrencyCacheTTL),
+	}
+	registerValidators(server.currencies)
+
 	router := gin.Default()
+	router.Use(requestIDMiddleware())
 
 	//Handle router
+	router.GET("/healthz", server.healthz)
+	router.GET("/readyz", server.readyz)
+	router.GET("/version", server.version)
+	router.GET("/currencies", server.listCurrencies)
+	router.GET("/fx/rates", server.listExchangeRates)
+
 	router.POST("/users", server.createUser)
+	router.POST("/users/login", server.loginUser)
+	router.POST("/tokens/renew_access", server.renewAccessToken)
+	router.GET("/.well-known/jwks.json", server.getJWKS)
 
-	router.POST("/accounts", server.createAccount)
-	router.GET("/accounts/:id", server.getAccount)
-	router.GET("/accounts/", server.listAccounts)
+	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker, server.sessions))
 
-	router.POST("/transfers", server.createTransfer)
+	authRoutes.GET("/sessions", server.listSessions)
+	authRoutes.DELETE("/sessions/:id", server.revokeSession)
+	authRoutes.POST("/logout", server.logoutUser)
+
+	authRoutes.POST("/accounts", requireScopes(token.ScopeAccountsWrite), server.createAccount)
+	authRoutes.GET("/accounts/:id", requireScopes(token.ScopeAccountsRead), server.getAccount)
+	authRoutes.GET("/accounts/", requireScopes(token.ScopeAccountsRead), server.listAccounts)
+	authRoutes.GET("/accounts/:id/entries", requireScopes(token.ScopeAccountsRead), server.listEntries)
+	authRoutes.POST("/accounts/:id/deposits", requireScopes(token.ScopeCashWrite), server.createDeposit)
+	authRoutes.POST("/accounts/:id/withdrawals", requireScopes(token.ScopeCashWrite), server.createWithdrawal)
+
+	authRoutes.POST("/transfers", requireScopes(token.ScopeTransfersWrite), server.createTransfer)
+	authRoutes.GET("/transfers", requireScopes(token.ScopeTransfersRead), server.listTransfers)
+	authRoutes.GET("/transfers/:id", requireScopes(token.ScopeTransfersRead), server.getTransfer)
+	authRoutes.POST("/fx/quotes", requireScopes(token.ScopeTransfersWrite), server.createFxQuote)
+	authRoutes.POST("/scheduled-transfers", requireScopes(token.ScopeTransfersWrite), server.createScheduledTransfer)
+	authRoutes.GET("/scheduled-transfers", requireScopes(token.ScopeTransfersRead), server.listScheduledTransfers)
+	authRoutes.PATCH("/scheduled-transfers/:id", requireScopes(token.ScopeTransfersWrite), server.updateScheduledTransfer)
+	authRoutes.GET("/scheduled-transfers/:id/runs", requireScopes(token.ScopeTransfersRead), server.listScheduledTransferRuns)
+
+	authRoutes.GET("/admin/currencies", requireScopes(token.ScopeAdmin), server.listAllCurrencies)
+	authRoutes.PATCH("/admin/currencies/:code", requireScopes(token.ScopeAdmin), server.updateCurrency)
+	authRoutes.POST("/admin/fx/rates", requireScopes(token.ScopeAdmin), server.createExchangeRate)
+	authRoutes.POST("/admin/journals", requireScopes(token.ScopeAdmin), server.createJournal)
+	authRoutes.GET("/admin/journals/:id", requireScopes(token.ScopeAdmin), server.getJournal)
+	authRoutes.GET("/admin/reconciliation", requireScopes(token.ScopeAdmin), server.getReconciliation)
+	authRoutes.POST("/admin/reconciliation", requireScopes(token.ScopeAdmin), server.runReconciliation)
+	authRoutes.GET("/debug/vars", requireScopes(token.ScopeAdmin), gin.WrapH(expvar.Handler()))
 
 	server.router = router
-	return
+	return server, nil
+}
+
+// newTokenMaker creates the token maker selected by config.TokenType
+func newTokenMaker(config util.Config) (token.Maker, error) {
+	switch config.TokenType {
+	case "jwt":
+		return token.NewJWTMaker(config.TokenSymmetricKey)
+	case "paseto", "":
+		return token.NewPasetoMaker(config.TokenSymmetricKey)
+	case "paseto-public":
+		return token.NewPasetoPublicMaker(config.TokenAsymmetricKey)
+	case "jwt-asymmetric":
+		return token.NewAsymmetricJWTMaker(config.TokenKeyDir, config.TokenSigningKeyID)
+	default:
+		return nil, fmt.Errorf("unsupported token type %q", config.TokenType)
+	}
 }
 
+// Start serves HTTP on address until the process receives SIGINT or SIGTERM,
+// then drains in-flight requests and closes the store.
 func (server *Server) Start(address string) error {
-	return server.router.Run(address)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("cannot listen on %s: %w", address, err)
+	}
+
+	return server.Serve(ctx, listener)
 }
 
-func errorResponse(err error) gin.H {
-	return gin.H{"error": err.Error()}
+// Serve handles requests on listener until ctx is done. Requests already in
+// flight get up to config.ShutdownTimeout to finish before their connections
+// are closed, and the store is closed once the server and its background
+// jobs have stopped.
+func (server *Server) Serve(ctx context.Context, listener net.Listener) error {
+	httpServer := &http.Server{Handler: server.router}
+
+	jobCtx, stopJobs := context.WithCancel(ctx)
+	var jobs sync.WaitGroup
+	if server.config.ReconcileInterval > 0 {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			server.runReconciler(jobCtx, server.config.ReconcileInterval)
+		}()
+	}
+	if server.config.SchedulerInterval > 0 {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			server.runScheduler(jobCtx, server.config.SchedulerInterval)
+		}()
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(listener)
+	}()
+
+	var err error
+	select {
+	case err = <-serveErr:
+	case <-ctx.Done():
+		slog.Info("shutting down server", "timeout", server.config.ShutdownTimeout)
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), server.config.ShutdownTimeout)
+		defer cancel()
+
+		if err = httpServer.Shutdown(shutdownCtx); err != nil {
+			err = fmt.Errorf("cannot drain in-flight requests: %w", err)
+			httpServer.Close()
+		}
+		<-serveErr
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+
+	stopJobs()
+	jobs.Wait()
+
+	if closeErr := server.store.Close(); closeErr != nil {
+		err = errors.Join(err, fmt.Errorf("cannot close store: %w", closeErr))
+	}
+	return err
 }
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gurukanth/simplebank/db/mock"
+	"github.com/gurukanth/simplebank/token"
+	"github.com/gurukanth/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func startTestServe(t *testing.T, server *Server) (string, context.CancelFunc, <-chan error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve(ctx, listener)
+	}()
+
+	return "http://" + listener.Addr().String(), cancel, done
+}
+
+func TestServeDrainsInFlightRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().Close().Times(1).Return(nil)
+
+	server := newTestServer(t, store)
+	server.config.ShutdownTimeout = 5 * time.Second
+
+	started := make(chan struct{})
+	server.router.GET("/slow", func(ctx *gin.Context) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		ctx.Status(http.StatusNoContent)
+	})
+
+	url, cancel, done := startTestServe(t, server)
+
+	type result struct {
+		resp *http.Response
+		err  error
+	}
+	results := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(url + "/slow")
+		if err == nil {
+			resp.Body.Close()
+		}
+		results <- result{resp, err}
+	}()
+
+	<-started
+	cancel()
+
+	res := <-results
+	require.NoError(t, res.err)
+	require.Equal(t, http.StatusNoContent, res.resp.StatusCode)
+	require.NoError(t, <-done)
+
+	_, err := http.Get(url + "/slow")
+	require.Error(t, err)
+}
+
+func TestServeShutdownTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().Close().Times(1).Return(nil)
+
+	server := newTestServer(t, store)
+	server.config.ShutdownTimeout = 50 * time.Millisecond
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	server.router.GET("/stuck", func(ctx *gin.Context) {
+		close(started)
+		<-release
+	})
+
+	url, cancel, done := startTestServe(t, server)
+	go http.Get(url + "/stuck")
+
+	<-started
+	cancel()
+
+	err := <-done
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestServeReportsStoreCloseError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	closeErr := errors.New("close failed")
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().Close().Times(1).Return(closeErr)
+
+	server := newTestServer(t, store)
+	server.config.ShutdownTimeout = time.Second
+
+	_, cancel, done := startTestServe(t, server)
+	cancel()
+
+	require.ErrorIs(t, <-done, closeErr)
+}
+
+func TestDebugVarsAdminOnly(t *testing.T) {
+	testCases := []struct {
+		name         string
+		setupAuth    func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		expectedCode int
+	}{
+		{
+			name: "Admin",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.AdminRole, time.Minute)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "Customer",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.CustomerRole, time.Minute)
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "NoAuthorization",
+			setupAuth:    func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			stubActiveSession(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/debug/vars", nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.expectedCode, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/gurukanth/simplebank/db/sqlc"
+)
+
+// maxSessionCacheEntries bounds the cache before stale entries are swept
+const maxSessionCacheEntries = 10000
+
+type sessionCacheEntry struct {
+	active    bool
+	expiresAt time.Time
+}
+
+// sessionCache remembers whether sessions are still active so the auth
+// middleware does not query Postgres on every request. Revocations made
+// through this server are applied immediately; revocations made elsewhere
+// are picked up once the cached entry expires.
+type sessionCache struct {
+	store   db.Store
+	ttl     time.Duration
+	mu      sync.RWMutex
+	entries map[uuid.UUID]sessionCacheEntry
+}
+
+func newSessionCache(store db.Store, ttl time.Duration) *sessionCache {
+	return &sessionCache{
+		store:   store,
+		ttl:     ttl,
+		entries: make(map[uuid.UUID]sessionCacheEntry),
+	}
+}
+
+// isActive reports whether the session exists, is not blocked and has not expired
+func (cache *sessionCache) isActive(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	now := time.Now()
+
+	cache.mu.RLock()
+	entry, ok := cache.entries[sessionID]
+	cache.mu.RUnlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.active, nil
+	}
+
+	session, err := cache.store.GetSession(ctx, sessionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			cache.set(sessionID, false, now.Add(cache.ttl))
+			return false, nil
+		}
+		return false, err
+	}
+
+	active := !session.IsBlocked && now.Before(session.ExpiredAt)
+	expiresAt := now.Add(cache.ttl)
+	if active && session.ExpiredAt.Before(expiresAt) {
+		expiresAt = session.ExpiredAt
+	}
+
+	cache.set(sessionID, active, expiresAt)
+	return active, nil
+}
+
+// revoke marks the sessions as inactive without waiting for the entries to expire
+func (cache *sessionCache) revoke(sessionIDs ...uuid.UUID) {
+	expiresAt := time.Now().Add(cache.ttl)
+	for _, sessionID := range sessionIDs {
+		cache.set(sessionID, false, expiresAt)
+	}
+}
+
+func (cache *sessionCache) set(sessionID uuid.UUID, active bool, expiresAt time.Time) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if len(cache.entries) >= maxSessionCacheEntries {
+		now := time.Now()
+		for id, entry := range cache.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(cache.entries, id)
+			}
+		}
+	}
+
+	cache.entries[sessionID] = sessionCacheEntry{active: active, expiresAt: expiresAt}
+}
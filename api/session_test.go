@@ -0,0 +1,250 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockdb "github.com/gurukanth/simplebank/db/mock"
+	db "github.com/gurukanth/simplebank/db/sqlc"
+	"github.com/gurukanth/simplebank/token"
+	"github.com/gurukanth/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListSessionsAPI(t *testing.T) {
+	username := util.RandomOwner()
+	sessions := []db.Session{
+		randomDBSession(username),
+		randomDBSession(username),
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	stubActiveSession(store)
+	store.EXPECT().
+		ListActiveSessions(gomock.Any(), gomock.Eq(username)).
+		Times(1).
+		Return(sessions, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/sessions", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, username, util.CustomerRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var rsp []sessionResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &rsp)
+	require.NoError(t, err)
+	require.Len(t, rsp, len(sessions))
+	for i, session := range sessions {
+		require.Equal(t, session.ID, rsp[i].ID)
+		require.Equal(t, session.UserAgent, rsp[i].UserAgent)
+		require.Equal(t, session.ClientIp, rsp[i].ClientIP)
+		require.False(t, rsp[i].Current)
+	}
+}
+
+func TestRevokeSessionAPI(t *testing.T) {
+	username := util.RandomOwner()
+	session := randomDBSession(username)
+
+	testCases := []struct {
+		name          string
+		sessionID     string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			sessionID: session.ID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.BlockSessionParams{
+					ID:       session.ID,
+					Username: username,
+				}
+				blocked := session
+				blocked.IsBlocked = true
+
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(blocked, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "NotFound",
+			sessionID: session.ID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidID",
+			sessionID: "not-a-uuid",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InternalError",
+			sessionID: session.ID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			stubActiveSession(store)
+
+			//build stubs
+			tc.buildStubs(store)
+			//start test server and send request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/sessions/%s", tc.sessionID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, username, util.CustomerRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+
+			//check response
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestLogoutRevokesCurrentSession(t *testing.T) {
+	username := util.RandomOwner()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	accessToken, payload, err := server.tokenMaker.CreateToken(token.AccessToken, username, util.CustomerRole, uuid.Nil, time.Minute)
+	require.NoError(t, err)
+
+	// the session is looked up once and then served from the cache
+	store.EXPECT().
+		GetSession(gomock.Any(), gomock.Eq(payload.SessionID)).
+		Times(1).
+		Return(db.Session{ID: payload.SessionID, Username: username, ExpiredAt: time.Now().Add(time.Hour)}, nil)
+	store.EXPECT().
+		BlockUserSessions(gomock.Any(), gomock.Eq(username)).
+		Times(1).
+		Return([]uuid.UUID{payload.SessionID}, nil)
+
+	authHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/logout", nil)
+	require.NoError(t, err)
+	request.Header.Set(authorizationHeaderKey, authHeader)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var rsp logoutUserResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &rsp)
+	require.NoError(t, err)
+	require.Equal(t, 1, rsp.RevokedSessions)
+
+	// the revoked access token is rejected without another database lookup
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodGet, "/sessions", nil)
+	require.NoError(t, err)
+	request.Header.Set(authorizationHeaderKey, authHeader)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	require.Contains(t, recorder.Body.String(), errRevokedSession.Error())
+}
+
+func TestSessionCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	sessionID := uuid.New()
+
+	store.EXPECT().
+		GetSession(gomock.Any(), gomock.Eq(sessionID)).
+		Times(2).
+		Return(db.Session{ID: sessionID, ExpiredAt: time.Now().Add(time.Hour)}, nil)
+
+	cache := newSessionCache(store, 50*time.Millisecond)
+
+	for range 3 {
+		active, err := cache.isActive(context.Background(), sessionID)
+		require.NoError(t, err)
+		require.True(t, active)
+	}
+
+	// the entry is refreshed from the store once the ttl has passed
+	time.Sleep(60 * time.Millisecond)
+	active, err := cache.isActive(context.Background(), sessionID)
+	require.NoError(t, err)
+	require.True(t, active)
+
+	cache.revoke(sessionID)
+	active, err = cache.isActive(context.Background(), sessionID)
+	require.NoError(t, err)
+	require.False(t, active)
+}
+
+func randomDBSession(username string) db.Session {
+	return db.Session{
+		ID:           uuid.New(),
+		Username:     username,
+		RefreshToken: util.RandomString(32),
+		UserAgent:    "test-agent",
+		ClientIp:     "127.0.0.1",
+		ExpiredAt:    time.Now().Add(time.Hour),
+		CreatedAt:    time.Now(),
+	}
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gurukanth/simplebank/token"
+)
+
+var (
+	errBlockedSession         = errors.New("blocked session")
+	errIncorrectSessionUser   = errors.New("incorrect session user")
+	errMismatchedSessionToken = errors.New("mismatched session token")
+	errExpiredSession         = errors.New("expired session")
+)
+
+type renewAccessTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type renewAccessTokenResponse struct {
+	AccessToken          string    `json:"access_token"`
+	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+}
+
+func (server *Server) renewAccessToken(ctx *gin.Context) {
+	var req renewAccessTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	refreshPayload, err := server.tokenMaker.VerifyToken(req.RefreshToken)
+	if err != nil {
+		respondError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+	if refreshPayload.Type != token.RefreshToken {
+		respondError(ctx, http.StatusUnauthorized, token.ErrorInvalidToken)
+		return
+	}
+
+	session, err := server.store.GetSession(ctx, refreshPayload.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, http.StatusNotFound, err)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if session.IsBlocked {
+		respondError(ctx, http.StatusUnauthorized, errBlockedSession)
+		return
+	}
+
+	if session.Username != refreshPayload.Username {
+		respondError(ctx, http.StatusUnauthorized, errIncorrectSessionUser)
+		return
+	}
+
+	if session.RefreshToken != req.RefreshToken {
+		respondError(ctx, http.StatusUnauthorized, errMismatchedSessionToken)
+		return
+	}
+
+	if time.Now().After(session.ExpiredAt) {
+		respondError(ctx, http.StatusUnauthorized, errExpiredSession)
+		return
+	}
+
+	// the role may have changed since login, and the new access token must
+	// carry the scopes of the current one
+	user, err := server.store.GetUser(ctx, session.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, http.StatusNotFound, err)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(token.AccessToken, user.Username, user.Role, session.ID, server.config.AccessTokenDuration)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	rsp := renewAccessTokenResponse{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: accessPayload.ExpiredAt,
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
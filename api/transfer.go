@@ -1,68 +1,374 @@
 package api
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	db "github.com/gurukanth/simplebank/db/sqlc"
+	"github.com/gurukanth/simplebank/token"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+var (
+	errInvalidIdempotencyKey  = fmt.Errorf("%s header must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength)
+	errReservedIdempotencyKey = fmt.Errorf("%s header must not start with %q", idempotencyKeyHeader, scheduledTransferKeyPrefix)
 )
 
 type transferRequest struct {
-	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
-	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
-	Amount        int64  `json:"amount" binding:"required,gt=0"`
-	Currency      string `json:"currency" binding:"required,oneof=USD EUR INR"`
+	FromAccountID int64       `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64       `json:"to_account_id" binding:"required,min=1"`
+	Amount        moneyAmount `json:"amount"`
+	Currency      string      `json:"currency" binding:"required,currency"`
+	// QuoteID makes this a cross-currency transfer at the rate of the quote.
+	// Amount and Currency are then those of the from account.
+	QuoteID string `json:"quote_id,omitempty" binding:"omitempty,uuid"`
 }
 
 func (server *Server) createTransfer(ctx *gin.Context) {
 	var req transferRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	if !server.validateAccount(ctx, req.FromAccountID, req.Currency) {
+	amount, valid := server.resolveAmount(ctx, req.Amount, req.Currency)
+	if !valid {
 		return
 	}
+	// hash the resolved amount so that "10.50" and 1050 are the same request
+	req.Amount = minorAmount(amount)
 
-	if !server.validateAccount(ctx, req.ToAccountID, req.Currency) {
+	fromAccount, valid := server.validateAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
 		return
 	}
 
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if fromAccount.Owner != authPayload.Username {
+		respondError(ctx, http.StatusForbidden, errAccountNotOwned)
+		return
+	}
+
+	// the currency of the to account is checked against the quote when the
+	// transfer is made
+	if req.QuoteID == "" {
+		_, valid = server.validateAccount(ctx, req.ToAccountID, req.Currency)
+		if !valid {
+			return
+		}
+	}
+
 	arg := db.TransferTxParams{
 		FromAccountId: req.FromAccountID,
 		ToAccountId:   req.ToAccountID,
-		Amount:        req.Amount,
+		Amount:        amount,
+	}
+
+	if key := ctx.GetHeader(idempotencyKeyHeader); key != "" {
+		if len(key) > maxIdempotencyKeyLength {
+			respondError(ctx, http.StatusBadRequest, errInvalidIdempotencyKey)
+			return
+		}
+		if strings.HasPrefix(key, scheduledTransferKeyPrefix) {
+			respondError(ctx, http.StatusBadRequest, errReservedIdempotencyKey)
+			return
+		}
+
+		requestHash, err := hashTransferRequest(req)
+		if err != nil {
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		arg.IdempotencyKey = key
+		arg.Username = authPayload.Username
+		arg.RequestHash = requestHash
 	}
 
-	result, err := server.store.TransferTx(ctx, arg)
+	var result db.TransferTxResult
+	var err error
+	if req.QuoteID == "" {
+		result, err = server.store.TransferTx(ctx, arg)
+	} else {
+		// the quote can only be used by the user it was given to
+		arg.Username = authPayload.Username
+		result, err = server.store.CrossCurrencyTransferTx(ctx, db.CrossCurrencyTransferTxParams{
+			TransferTxParams: arg,
+			QuoteID:          uuid.MustParse(req.QuoteID),
+		})
+	}
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		switch {
+		case errors.Is(err, db.ErrInsufficientFunds),
+			errors.Is(err, db.ErrFxQuoteUnavailable),
+			errors.Is(err, db.ErrFxAmountTooSmall):
+			respondError(ctx, http.StatusUnprocessableEntity, err)
+		case errors.Is(err, db.ErrFxQuoteMismatch):
+			respondError(ctx, http.StatusBadRequest, err)
+		case errors.Is(err, db.ErrIdempotencyKeyReused):
+			respondError(ctx, http.StatusConflict, err)
+		case errors.Is(err, sql.ErrNoRows):
+			respondError(ctx, http.StatusNotFound, err)
+		default:
+			respondError(ctx, http.StatusInternalServerError, err)
+		}
 		return
 	}
 
+	if result.Replayed {
+		ctx.Header(idempotentReplayedHeader, "true")
+	}
 	ctx.JSON(http.StatusOK, result)
 }
 
-func (server *Server) validateAccount(ctx *gin.Context, accountID int64, currency string) bool {
+// hashTransferRequest fingerprints a transfer request so that a replayed
+// idempotency key can be checked against the body it was first used with
+func hashTransferRequest(req transferRequest) (string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (server *Server) validateAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
 	account, err := server.store.GetAccount(ctx, accountID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return false
+			respondError(ctx, http.StatusNotFound, err)
+			return account, false
 		}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return false
+		respondError(ctx, http.StatusInternalServerError, err)
+		return account, false
 	}
 	if account.Currency != currency {
 		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", accountID, account.Currency, currency)
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return false
+		respondError(ctx, http.StatusBadRequest, err)
+		return account, false
+	}
+
+	return account, true
+
+}
+
+var errTransferNotOwned = errors.New("transfer doesn't involve the authenticated user")
+
+type transferResponse struct {
+	ID            int64     `json:"id"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+	FromOwner     string    `json:"from_owner"`
+	ToOwner       string    `json:"to_owner"`
+	Currency      string    `json:"currency"`
+	ToCurrency    string    `json:"to_currency"`
+	// ToAmount, FxRate and FxSpreadBps are set for cross-currency transfers
+	ToAmount    *int64  `json:"to_amount,omitempty"`
+	FxRate      *string `json:"fx_rate,omitempty"`
+	FxSpreadBps *int32  `json:"fx_spread_bps,omitempty"`
+}
+
+// newTransferResponse converts a row of GetTransferWithOwners. The rows of
+// the ListUserTransfers queries have the same columns and convert to it.
+func newTransferResponse(transfer db.GetTransferWithOwnersRow) transferResponse {
+	rsp := transferResponse{
+		ID:            transfer.ID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		CreatedAt:     transfer.CreatedAt,
+		FromOwner:     transfer.FromOwner,
+		ToOwner:       transfer.ToOwner,
+		Currency:      transfer.Currency,
+		ToCurrency:    transfer.ToCurrency,
 	}
+	if transfer.ToAmount.Valid {
+		toAmount := transfer.ToAmount.Int64
+		rsp.ToAmount = &toAmount
+	}
+	if transfer.FxRate.Valid {
+		fxRate := transfer.FxRate.String
+		rsp.FxRate = &fxRate
+	}
+	if transfer.FxSpreadBps.Valid {
+		fxSpreadBps := transfer.FxSpreadBps.Int32
+		rsp.FxSpreadBps = &fxSpreadBps
+	}
+	return rsp
+}
+
+type getTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
 
-	return true
+func (server *Server) getTransfer(ctx *gin.Context) {
+	var req getTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	transfer, err := server.store.GetTransferWithOwners(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, http.StatusNotFound, err)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if transfer.FromOwner != authPayload.Username && transfer.ToOwner != authPayload.Username {
+		respondError(ctx, http.StatusForbidden, errTransferNotOwned)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newTransferResponse(transfer))
+}
+
+type listTransfersRequest struct {
+	Counterparty string    `form:"counterparty" binding:"omitempty,alphanum"`
+	MinAmount    *int64    `form:"min_amount" binding:"omitempty,gt=0"`
+	MaxAmount    *int64    `form:"max_amount" binding:"omitempty,gt=0"`
+	Currency     string    `form:"currency" binding:"omitempty,currency"` // either side of the transfer
+	From         time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To           time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	PageId       int32     `form:"page_id" binding:"omitempty,min=1"`
+	PageSize     int32     `form:"page_size" binding:"required,min=5,max=100"`
+	Cursor       string    `form:"cursor"`
+}
+
+var errInvalidAmountRange = errors.New("min_amount must not be greater than max_amount")
+
+func (server *Server) listTransfers(ctx *gin.Context) {
+	var req listTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
+		respondError(ctx, http.StatusBadRequest, errInvalidAmountRange)
+		return
+	}
+	if !req.From.IsZero() && !req.To.IsZero() && !req.From.Before(req.To) {
+		respondError(ctx, http.StatusBadRequest, errInvalidTimeRange)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.ListUserTransfersParams{
+		Owner:        authPayload.Username,
+		Counterparty: sql.NullString{String: req.Counterparty, Valid: req.Counterparty != ""},
+		Currency:     sql.NullString{String: req.Currency, Valid: req.Currency != ""},
+		FromTime:     sql.NullTime{Time: req.From, Valid: !req.From.IsZero()},
+		ToTime:       sql.NullTime{Time: req.To, Valid: !req.To.IsZero()},
+		LimitCount:   req.PageSize,
+		OffsetCount:  (req.PageId - 1) * req.PageSize,
+	}
+	if req.MinAmount != nil {
+		arg.MinAmount = sql.NullInt64{Int64: *req.MinAmount, Valid: true}
+	}
+	if req.MaxAmount != nil {
+		arg.MaxAmount = sql.NullInt64{Int64: *req.MaxAmount, Valid: true}
+	}
+
+	if req.PageId == 0 {
+		server.listTransfersByCursor(ctx, arg, req)
+		return
+	}
+
+	transfers, err := server.store.ListUserTransfers(ctx, arg)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	rsp := make([]transferResponse, len(transfers))
+	for i, transfer := range transfers {
+		rsp[i] = newTransferResponse(db.GetTransferWithOwnersRow(transfer))
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// listTransfersByCursor pages newest first, so rows after the cursor are the
+// older ones
+func (server *Server) listTransfersByCursor(ctx *gin.Context, arg db.ListUserTransfersParams, req listTransfersRequest) {
+	cursor := firstPageCursor()
+	if req.Cursor != "" {
+		var err error
+		if cursor, err = server.cursors.decode(req.Cursor); err != nil {
+			respondError(ctx, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	var transfers []transferResponse
+	if cursor.Before {
+		rows, err := server.store.ListUserTransfersBefore(ctx, db.ListUserTransfersBeforeParams{
+			Owner:           arg.Owner,
+			Counterparty:    arg.Counterparty,
+			MinAmount:       arg.MinAmount,
+			MaxAmount:       arg.MaxAmount,
+			Currency:        arg.Currency,
+			FromTime:        arg.FromTime,
+			ToTime:          arg.ToTime,
+			BeforeCreatedAt: cursor.CreatedAt,
+			BeforeID:        cursor.ID,
+			LimitCount:      req.PageSize + 1,
+		})
+		if err != nil {
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		for _, row := range rows {
+			transfers = append(transfers, newTransferResponse(db.GetTransferWithOwnersRow(row)))
+		}
+		reverse(transfers)
+	} else {
+		rows, err := server.store.ListUserTransfersAfter(ctx, db.ListUserTransfersAfterParams{
+			Owner:          arg.Owner,
+			Counterparty:   arg.Counterparty,
+			MinAmount:      arg.MinAmount,
+			MaxAmount:      arg.MaxAmount,
+			Currency:       arg.Currency,
+			FromTime:       arg.FromTime,
+			ToTime:         arg.ToTime,
+			AfterCreatedAt: cursor.CreatedAt,
+			AfterID:        cursor.ID,
+			LimitCount:     req.PageSize + 1,
+		})
+		if err != nil {
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		for _, row := range rows {
+			transfers = append(transfers, newTransferResponse(db.GetTransferWithOwnersRow(row)))
+		}
+	}
 
+	page := newCursorPage(server.cursors, transfers, int(req.PageSize), cursor, req.Cursor != "", func(transfer transferResponse) pageCursor {
+		return pageCursor{ID: transfer.ID, CreatedAt: transfer.CreatedAt}
+	})
+	ctx.JSON(http.StatusOK, page)
 }
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// currencyLookupTimeout bounds how long binding a request waits for the
+// currencies table to load
+const currencyLookupTimeout = 2 * time.Second
+
+var (
+	registerValidatorsOnce sync.Once
+	// validatorCurrencies is the registry of the latest server. gin binds
+	// with a single process-wide validator, so it cannot tell servers apart.
+	validatorCurrencies atomic.Pointer[currencyRegistry]
+)
+
+// registerValidators installs the custom validations on gin's validator
+// once, and points the currency validation at registry
+func registerValidators(registry *currencyRegistry) {
+	validatorCurrencies.Store(registry)
+
+	registerValidatorsOnce.Do(func() {
+		if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+			v.RegisterValidation("currency", validateCurrency)
+			v.RegisterTagNameFunc(fieldName)
+		}
+	})
+}
+
+// validateCurrency accepts the currencies enabled in the registry
+func validateCurrency(fl validator.FieldLevel) bool {
+	code, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	registry := validatorCurrencies.Load()
+	if registry == nil {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), currencyLookupTimeout)
+	defer cancel()
+
+	_, enabled, err := registry.get(ctx, code)
+	if err != nil {
+		slog.Error("cannot load currencies", "error", err)
+		return false
+	}
+	return enabled
+}
@@ -0,0 +1,10 @@
+-- the system user owns one cash account per currency, it is the other side of
+-- every deposit and withdrawal so that entries always sum to zero
+INSERT INTO "users" ("username", "full_name", "email", "hashed_password", "role")
+VALUES ('system', 'System', 'system@simplebank.local', '!', 'system');
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+VALUES
+  ('system', 0, 'USD'),
+  ('system', 0, 'EUR'),
+  ('system', 0, 'INR');
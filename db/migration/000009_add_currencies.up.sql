@@ -0,0 +1,24 @@
+CREATE TABLE "currencies" (
+  "code" varchar(3) PRIMARY KEY,
+  "numeric_code" int UNIQUE NOT NULL,
+  "exponent" smallint NOT NULL,
+  "enabled" boolean NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "currencies" ADD CONSTRAINT "exponent_range" CHECK ("exponent" BETWEEN 0 AND 4);
+
+COMMENT ON COLUMN "currencies"."code" IS 'ISO 4217 alphabetic code';
+COMMENT ON COLUMN "currencies"."numeric_code" IS 'ISO 4217 numeric code';
+COMMENT ON COLUMN "currencies"."exponent" IS 'number of minor-unit digits, 2 when amounts are in cents';
+
+INSERT INTO "currencies" ("code", "numeric_code", "exponent", "enabled")
+VALUES
+  ('USD', 840, 2, true),
+  ('EUR', 978, 2, true),
+  ('INR', 356, 2, true),
+  ('GBP', 826, 2, false),
+  ('JPY', 392, 0, false),
+  ('KWD', 414, 3, false);
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
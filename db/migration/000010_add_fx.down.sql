@@ -0,0 +1,13 @@
+DELETE FROM "entries" WHERE "transfer_id" IN (SELECT "id" FROM "transfers" WHERE "fx_quote_id" IS NOT NULL);
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'fxhouse');
+DELETE FROM "transfers" WHERE "fx_quote_id" IS NOT NULL;
+DELETE FROM "accounts" WHERE "owner" = 'fxhouse';
+DELETE FROM "users" WHERE "username" = 'fxhouse';
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "fx_quote_id";
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "fx_spread_bps";
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "fx_rate";
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "to_amount";
+
+DROP TABLE IF EXISTS "fx_quotes";
+DROP TABLE IF EXISTS "exchange_rates";
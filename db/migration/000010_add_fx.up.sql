@@ -0,0 +1,55 @@
+CREATE TABLE "exchange_rates" (
+  "id" bigserial PRIMARY KEY,
+  "base_currency" varchar(3) NOT NULL,
+  "quote_currency" varchar(3) NOT NULL,
+  "rate" numeric(20,10) NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "exchange_rates" ADD FOREIGN KEY ("base_currency") REFERENCES "currencies" ("code");
+ALTER TABLE "exchange_rates" ADD FOREIGN KEY ("quote_currency") REFERENCES "currencies" ("code");
+ALTER TABLE "exchange_rates" ADD CONSTRAINT "rate_positive" CHECK ("rate" > 0);
+ALTER TABLE "exchange_rates" ADD CONSTRAINT "distinct_currencies" CHECK ("base_currency" <> "quote_currency");
+
+CREATE INDEX ON "exchange_rates" ("base_currency", "quote_currency", "created_at");
+
+COMMENT ON COLUMN "exchange_rates"."rate" IS 'units of quote_currency for one unit of base_currency';
+
+CREATE TABLE "fx_quotes" (
+  "id" uuid PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_currency" varchar(3) NOT NULL,
+  "to_currency" varchar(3) NOT NULL,
+  "rate" numeric(20,10) NOT NULL,
+  "spread_bps" int NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "fx_quotes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+ALTER TABLE "fx_quotes" ADD FOREIGN KEY ("from_currency") REFERENCES "currencies" ("code");
+ALTER TABLE "fx_quotes" ADD FOREIGN KEY ("to_currency") REFERENCES "currencies" ("code");
+
+COMMENT ON COLUMN "fx_quotes"."rate" IS 'mid-market rate, units of to_currency for one unit of from_currency';
+COMMENT ON COLUMN "fx_quotes"."spread_bps" IS 'margin taken off the rate, in basis points';
+
+ALTER TABLE "transfers" ADD COLUMN "to_amount" bigint;
+ALTER TABLE "transfers" ADD COLUMN "fx_rate" numeric(20,10);
+ALTER TABLE "transfers" ADD COLUMN "fx_spread_bps" int;
+ALTER TABLE "transfers" ADD COLUMN "fx_quote_id" uuid;
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("fx_quote_id") REFERENCES "fx_quotes" ("id");
+
+COMMENT ON COLUMN "transfers"."to_amount" IS 'amount credited in the currency of to_account_id, set for cross-currency transfers';
+COMMENT ON COLUMN "transfers"."fx_rate" IS 'mid-market rate of the quote the transfer used';
+COMMENT ON COLUMN "transfers"."fx_spread_bps" IS 'spread taken off fx_rate, in basis points';
+
+-- the fx house owns one account per currency. A cross-currency transfer pays
+-- into the house account of the source currency and out of the house account
+-- of the target currency, so the entries of each currency sum to zero
+INSERT INTO "users" ("username", "full_name", "email", "hashed_password", "role")
+VALUES ('fxhouse', 'FX House', 'fxhouse@simplebank.local', '!', 'system');
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+SELECT 'fxhouse', 0, "code" FROM "currencies";
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS "journal_balanced" ON "entries";
+DROP FUNCTION IF EXISTS "check_journal_balanced"();
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "journal_id";
+
+DROP TABLE IF EXISTS "journal_transactions";
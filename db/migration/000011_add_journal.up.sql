@@ -0,0 +1,83 @@
+CREATE TABLE "journal_transactions" (
+  "id" bigserial PRIMARY KEY,
+  "kind" varchar NOT NULL,
+  "description" varchar NOT NULL DEFAULT '',
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "journal_transactions" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE UNIQUE INDEX ON "journal_transactions" ("transfer_id");
+
+COMMENT ON COLUMN "journal_transactions"."kind" IS 'transfer, fx_transfer, deposit, withdrawal, or the kind of a manual journal such as fee';
+COMMENT ON COLUMN "journal_transactions"."transfer_id" IS 'set when the journal was written by a transfer';
+
+ALTER TABLE "entries" ADD COLUMN "journal_id" bigint;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("journal_id") REFERENCES "journal_transactions" ("id");
+
+CREATE INDEX ON "entries" ("journal_id");
+
+COMMENT ON COLUMN "entries"."journal_id" IS 'the entries of a journal sum to zero in each currency';
+
+-- every transfer gets the journal it would have been written with
+INSERT INTO "journal_transactions" ("kind", "transfer_id", "created_at")
+SELECT CASE WHEN "fx_quote_id" IS NULL THEN 'transfer' ELSE 'fx_transfer' END, "id", "created_at"
+FROM "transfers";
+
+UPDATE "entries" e
+SET "journal_id" = j."id"
+FROM "journal_transactions" j
+WHERE j."transfer_id" = e."transfer_id";
+
+-- deposits and withdrawals did not record which entries belong together, so
+-- their entries share a single legacy journal. Its entries are never written
+-- again, so the balance check below never runs on it.
+WITH legacy AS (
+  INSERT INTO "journal_transactions" ("kind", "description")
+  SELECT 'legacy', 'entries written before journals existed'
+  WHERE EXISTS (SELECT 1 FROM "entries" WHERE "journal_id" IS NULL)
+  RETURNING "id"
+)
+UPDATE "entries"
+SET "journal_id" = (SELECT "id" FROM legacy)
+WHERE "journal_id" IS NULL;
+
+ALTER TABLE "entries" ALTER COLUMN "journal_id" SET NOT NULL;
+
+-- the entries of a journal must sum to zero in each currency. The check is
+-- deferred to commit so that the legs of a journal can be inserted one by one.
+CREATE FUNCTION "check_journal_balanced"() RETURNS trigger AS $$
+DECLARE
+  journal bigint;
+  unbalanced varchar;
+BEGIN
+  FOREACH journal IN ARRAY ARRAY[
+    CASE WHEN TG_OP <> 'INSERT' THEN OLD."journal_id" END,
+    CASE WHEN TG_OP <> 'DELETE' THEN NEW."journal_id" END
+  ] LOOP
+    CONTINUE WHEN journal IS NULL;
+
+    SELECT a."currency" INTO unbalanced
+    FROM "entries" e
+    JOIN "accounts" a ON a."id" = e."account_id"
+    WHERE e."journal_id" = journal
+    GROUP BY a."currency"
+    HAVING SUM(e."amount") <> 0
+    LIMIT 1;
+
+    IF unbalanced IS NOT NULL THEN
+      RAISE EXCEPTION 'journal % does not balance in %', journal, unbalanced
+        USING ERRCODE = 'check_violation', CONSTRAINT = 'journal_balanced';
+    END IF;
+  END LOOP;
+
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER "journal_balanced"
+AFTER INSERT OR UPDATE OF "account_id", "amount", "journal_id" OR DELETE ON "entries"
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW EXECUTE FUNCTION "check_journal_balanced"();
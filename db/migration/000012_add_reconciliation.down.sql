@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "reconciliation_runs";
+DROP TABLE IF EXISTS "account_entry_sums";
@@ -0,0 +1,27 @@
+CREATE TABLE "account_entry_sums" (
+  "account_id" bigint PRIMARY KEY,
+  "entry_sum" bigint NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "account_entry_sums" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+COMMENT ON COLUMN "account_entry_sums"."entry_sum" IS 'sum of the entries of the account up to the entry_checkpoint of the latest reconciliation run';
+
+CREATE TABLE "reconciliation_runs" (
+  "id" bigserial PRIMARY KEY,
+  "status" varchar NOT NULL,
+  "entry_checkpoint" bigint NOT NULL,
+  "transfer_checkpoint" bigint NOT NULL,
+  "accounts_checked" int NOT NULL,
+  "mismatched_accounts" int NOT NULL,
+  "orphan_entries" int NOT NULL,
+  "orphan_transfers" int NOT NULL,
+  "details" jsonb NOT NULL,
+  "started_at" timestamptz NOT NULL,
+  "finished_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "reconciliation_runs"."status" IS 'ok, or drift when any mismatch or orphan was found';
+COMMENT ON COLUMN "reconciliation_runs"."entry_checkpoint" IS 'highest entry id folded into account_entry_sums';
+COMMENT ON COLUMN "reconciliation_runs"."transfer_checkpoint" IS 'highest transfer id checked for a journal';
@@ -0,0 +1,56 @@
+CREATE TABLE "scheduled_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "frequency" varchar NOT NULL,
+  "start_at" timestamptz NOT NULL,
+  "end_at" timestamptz,
+  "max_runs" int,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "occurrence" int NOT NULL DEFAULT 0,
+  "next_run_at" timestamptz NOT NULL,
+  "runs_done" int NOT NULL DEFAULT 0,
+  "failure_count" int NOT NULL DEFAULT 0,
+  "claimed_until" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+ALTER TABLE "scheduled_transfers" ADD CONSTRAINT "scheduled_amount_positive" CHECK ("amount" > 0);
+ALTER TABLE "scheduled_transfers" ADD CONSTRAINT "scheduled_frequency_known" CHECK ("frequency" IN ('once', 'daily', 'weekly', 'monthly'));
+ALTER TABLE "scheduled_transfers" ADD CONSTRAINT "scheduled_status_known" CHECK ("status" IN ('active', 'paused', 'completed', 'cancelled'));
+
+CREATE INDEX ON "scheduled_transfers" ("owner");
+CREATE INDEX ON "scheduled_transfers" ("next_run_at") WHERE "status" = 'active';
+
+COMMENT ON COLUMN "scheduled_transfers"."frequency" IS 'once, daily, weekly or monthly';
+COMMENT ON COLUMN "scheduled_transfers"."end_at" IS 'no occurrence of a standing order runs after this time';
+COMMENT ON COLUMN "scheduled_transfers"."max_runs" IS 'the standing order completes after this many successful runs';
+COMMENT ON COLUMN "scheduled_transfers"."status" IS 'active, paused, completed or cancelled';
+COMMENT ON COLUMN "scheduled_transfers"."occurrence" IS 'index of the pending occurrence, counting from start_at';
+COMMENT ON COLUMN "scheduled_transfers"."next_run_at" IS 'when the pending occurrence is next attempted';
+COMMENT ON COLUMN "scheduled_transfers"."failure_count" IS 'consecutive insufficient-funds failures of the pending occurrence';
+COMMENT ON COLUMN "scheduled_transfers"."claimed_until" IS 'set while a scheduler is running the pending occurrence';
+
+CREATE TABLE "scheduled_transfer_runs" (
+  "id" bigserial PRIMARY KEY,
+  "scheduled_transfer_id" bigint NOT NULL,
+  "occurrence" int NOT NULL,
+  "status" varchar NOT NULL,
+  "transfer_id" bigint,
+  "error" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id") ON DELETE CASCADE;
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id");
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."status" IS 'succeeded or failed';
+COMMENT ON COLUMN "scheduled_transfer_runs"."transfer_id" IS 'set when the run succeeded';
@@ -0,0 +1,16 @@
+-- deposits and withdrawals in these currencies cannot be undone here: dropping
+-- the cash leg alone would unbalance their journals, and dropping whole
+-- journals would leave the customer balances without their entries
+DO $$
+BEGIN
+  IF EXISTS (
+    SELECT 1 FROM "entries" e
+    JOIN "accounts" a ON a."id" = e."account_id"
+    WHERE a."owner" = 'system' AND a."currency" NOT IN ('USD', 'EUR', 'INR')
+  ) THEN
+    RAISE EXCEPTION 'system cash accounts outside USD, EUR and INR have entries, reverse them before migrating down';
+  END IF;
+END;
+$$;
+
+DELETE FROM "accounts" WHERE "owner" = 'system' AND "currency" NOT IN ('USD', 'EUR', 'INR');
@@ -0,0 +1,7 @@
+-- 000007 only opened system cash accounts for the currencies of the time, so
+-- currencies added since had nothing to pair deposits and withdrawals with
+INSERT INTO "accounts" ("owner", "balance", "currency")
+SELECT 'system', 0, c."code" FROM "currencies" c
+WHERE NOT EXISTS (
+  SELECT 1 FROM "accounts" a WHERE a."owner" = 'system' AND a."currency" = c."code"
+);
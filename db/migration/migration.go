@@ -0,0 +1,84 @@
+// Package migration embeds the SQL schema migrations so the binary and the
+// tests can apply them without the external migrate CLI.
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+// New returns a migrator that applies the embedded migrations to conn.
+// The migrator runs on a connection of its own taken from the pool, so
+// closing it releases that connection but leaves conn open. The driver made
+// by postgres.WithInstance would close conn as well.
+func New(conn *sql.DB) (*migrate.Migrate, error) {
+	source, err := iofs.New(FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("cannot open embedded migrations: %w", err)
+	}
+
+	ctx := context.Background()
+	dbConn, err := conn.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get migration connection: %w", err)
+	}
+
+	driver, err := postgres.WithConnection(ctx, dbConn, &postgres.Config{})
+	if err != nil {
+		dbConn.Close()
+		return nil, fmt.Errorf("cannot create migration driver: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, "postgres", driver)
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+// Up applies every pending migration to conn. A schema that is already up to
+// date is not an error.
+func Up(conn *sql.DB) error {
+	m, err := New(conn)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err = m.Up(); err != nil && err != migrate.ErrNoChange {
+		return err
+	}
+	return nil
+}
+
+// LatestVersion returns the version of the newest embedded migration
+func LatestVersion() int64 {
+	names, err := fs.Glob(FS, "*.up.sql")
+	if err != nil {
+		panic(err)
+	}
+
+	var latest int64
+	for _, name := range names {
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			panic(fmt.Sprintf("migration %s has no numeric version: %v", name, err))
+		}
+		latest = max(latest, version)
+	}
+	return latest
+}
@@ -1,9 +1,11 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  transfer_id,
+  journal_id
 ) VALUES (
-  $1, $2
+  $1, $2, $3, $4
 ) RETURNING *;
 
 -- name: GetEntry :one
@@ -15,4 +17,88 @@ SELECT * FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
-OFFSET $3;
\ No newline at end of file
+OFFSET $3;
+
+-- name: ListJournalEntries :many
+SELECT * FROM entries
+WHERE journal_id = $1
+ORDER BY id;
+
+-- name: ListAccountStatement :many
+-- running_balance is the account balance less every entry made after the
+-- row, so the window reads all entries of the account from from_time up to
+-- now, not only those of the page. The cost grows with how far back from_time
+-- is, and the (account_id, created_at) index bounds it to that range.
+SELECT id, account_id, amount, transfer_id, created_at, running_balance
+FROM (
+  SELECT
+    e.id,
+    e.account_id,
+    e.amount,
+    e.transfer_id,
+    e.created_at,
+    (a.balance - SUM(e.amount) OVER (ORDER BY e.created_at DESC, e.id DESC) + e.amount)::bigint AS running_balance
+  FROM entries e
+  JOIN accounts a ON a.id = e.account_id
+  WHERE e.account_id = sqlc.arg(account_id)
+    AND e.created_at >= sqlc.arg(from_time)
+) AS statement
+WHERE created_at < sqlc.arg(to_time)
+  AND (
+    sqlc.arg(direction)::varchar = ''
+    OR (sqlc.arg(direction) = 'credit' AND amount > 0)
+    OR (sqlc.arg(direction) = 'debit' AND amount < 0)
+  )
+ORDER BY created_at, id
+LIMIT sqlc.arg(limit_count)
+OFFSET sqlc.arg(offset_count);
+
+-- name: ListAccountStatementAfter :many
+SELECT id, account_id, amount, transfer_id, created_at, running_balance
+FROM (
+  SELECT
+    e.id,
+    e.account_id,
+    e.amount,
+    e.transfer_id,
+    e.created_at,
+    (a.balance - SUM(e.amount) OVER (ORDER BY e.created_at DESC, e.id DESC) + e.amount)::bigint AS running_balance
+  FROM entries e
+  JOIN accounts a ON a.id = e.account_id
+  WHERE e.account_id = sqlc.arg(account_id)
+    AND e.created_at >= sqlc.arg(from_time)
+) AS statement
+WHERE created_at < sqlc.arg(to_time)
+  AND (
+    sqlc.arg(direction)::varchar = ''
+    OR (sqlc.arg(direction) = 'credit' AND amount > 0)
+    OR (sqlc.arg(direction) = 'debit' AND amount < 0)
+  )
+  AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg(limit_count);
+
+-- name: ListAccountStatementBefore :many
+SELECT id, account_id, amount, transfer_id, created_at, running_balance
+FROM (
+  SELECT
+    e.id,
+    e.account_id,
+    e.amount,
+    e.transfer_id,
+    e.created_at,
+    (a.balance - SUM(e.amount) OVER (ORDER BY e.created_at DESC, e.id DESC) + e.amount)::bigint AS running_balance
+  FROM entries e
+  JOIN accounts a ON a.id = e.account_id
+  WHERE e.account_id = sqlc.arg(account_id)
+    AND e.created_at >= sqlc.arg(from_time)
+) AS statement
+WHERE created_at < sqlc.arg(to_time)
+  AND (
+    sqlc.arg(direction)::varchar = ''
+    OR (sqlc.arg(direction) = 'credit' AND amount > 0)
+    OR (sqlc.arg(direction) = 'debit' AND amount < 0)
+  )
+  AND (created_at, id) < (sqlc.arg(before_created_at)::timestamptz, sqlc.arg(before_id)::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(limit_count);
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- name: CreateExchangeRate :one
+INSERT INTO exchange_rates (
+  base_currency,
+  quote_currency,
+  rate
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetLatestExchangeRate :one
+SELECT * FROM exchange_rates
+WHERE base_currency = $1 AND quote_currency = $2
+ORDER BY created_at DESC, id DESC
+LIMIT 1;
+
+-- name: ListLatestExchangeRates :many
+SELECT DISTINCT ON (base_currency, quote_currency) * FROM exchange_rates
+ORDER BY base_currency, quote_currency, created_at DESC, id DESC;
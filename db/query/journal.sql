@@ -0,0 +1,12 @@
+-- name: CreateJournalTransaction :one
+INSERT INTO journal_transactions (
+  kind,
+  description,
+  transfer_id
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetJournalTransaction :one
+SELECT * FROM journal_transactions
+WHERE id = $1 LIMIT 1;
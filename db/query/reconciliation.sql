@@ -0,0 +1,72 @@
+-- name: TryLockReconciliation :one
+SELECT pg_try_advisory_xact_lock(hashtext('reconciliation'));
+
+-- name: GetLatestReconciliationRun :one
+SELECT * FROM reconciliation_runs
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: CreateReconciliationRun :one
+INSERT INTO reconciliation_runs (
+  status,
+  entry_checkpoint,
+  transfer_checkpoint,
+  accounts_checked,
+  mismatched_accounts,
+  orphan_entries,
+  orphan_transfers,
+  details,
+  started_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: GetEntryCheckpoint :one
+SELECT COALESCE(MAX(id), sqlc.arg(after_id)::bigint)::bigint FROM entries
+WHERE id > sqlc.arg(after_id) AND created_at < sqlc.arg(settled_before);
+
+-- name: GetTransferCheckpoint :one
+SELECT COALESCE(MAX(id), sqlc.arg(after_id)::bigint)::bigint FROM transfers
+WHERE id > sqlc.arg(after_id) AND created_at < sqlc.arg(settled_before);
+
+-- name: AddAccountEntrySums :exec
+INSERT INTO account_entry_sums (account_id, entry_sum)
+SELECT account_id, SUM(amount)::bigint FROM entries
+WHERE id > sqlc.arg(after_id) AND id <= sqlc.arg(up_to_id)
+GROUP BY account_id
+ON CONFLICT (account_id) DO UPDATE
+SET entry_sum = account_entry_sums.entry_sum + EXCLUDED.entry_sum,
+    updated_at = now();
+
+-- name: CountAccounts :one
+SELECT count(*) FROM accounts;
+
+-- name: ListBalanceMismatches :many
+SELECT
+  a.id AS account_id,
+  a.balance,
+  (COALESCE(s.entry_sum, 0) + COALESCE(tail.entry_sum, 0))::bigint AS entry_sum
+FROM accounts a
+LEFT JOIN account_entry_sums s ON s.account_id = a.id
+LEFT JOIN (
+  SELECT account_id, SUM(amount) AS entry_sum FROM entries
+  WHERE id > sqlc.arg(checkpoint)
+  GROUP BY account_id
+) tail ON tail.account_id = a.id
+WHERE a.balance <> COALESCE(s.entry_sum, 0) + COALESCE(tail.entry_sum, 0)
+ORDER BY a.id;
+
+-- name: ListOrphanEntries :many
+SELECT e.id FROM entries e
+JOIN journal_transactions j ON j.id = e.journal_id
+WHERE e.id > sqlc.arg(after_id) AND e.id <= sqlc.arg(up_to_id)
+  AND e.transfer_id IS NOT NULL
+  AND j.transfer_id IS DISTINCT FROM e.transfer_id
+ORDER BY e.id;
+
+-- name: ListOrphanTransfers :many
+SELECT t.id FROM transfers t
+LEFT JOIN journal_transactions j ON j.transfer_id = t.id
+WHERE t.id > sqlc.arg(after_id) AND t.id <= sqlc.arg(up_to_id)
+  AND j.id IS NULL
+ORDER BY t.id;
@@ -0,0 +1,85 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+  owner,
+  from_account_id,
+  to_account_id,
+  amount,
+  frequency,
+  start_at,
+  end_at,
+  max_runs,
+  next_run_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $6
+) RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: GetScheduledTransferForUpdate :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: ClaimDueScheduledTransfers :many
+UPDATE scheduled_transfers
+SET claimed_until = now() + sqlc.arg(lease_seconds)::int * interval '1 second'
+WHERE id IN (
+  SELECT id FROM scheduled_transfers
+  WHERE status = 'active'
+    AND next_run_at <= now()
+    AND (claimed_until IS NULL OR claimed_until < now())
+  ORDER BY next_run_at
+  LIMIT sqlc.arg(batch_size)
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: UpdateScheduledTransferProgress :one
+UPDATE scheduled_transfers
+SET
+  status = $2,
+  occurrence = $3,
+  next_run_at = $4,
+  runs_done = $5,
+  failure_count = $6,
+  claimed_until = NULL,
+  updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateScheduledTransferStatus :one
+UPDATE scheduled_transfers
+SET
+  status = sqlc.arg(status),
+  failure_count = CASE WHEN sqlc.arg(status) = 'active' THEN 0 ELSE failure_count END,
+  next_run_at = CASE WHEN sqlc.arg(status) = 'active' THEN GREATEST(next_run_at, now()) ELSE next_run_at END,
+  updated_at = now()
+WHERE id = sqlc.arg(id) AND status IN ('active', 'paused')
+RETURNING *;
+
+-- name: CreateScheduledTransferRun :one
+INSERT INTO scheduled_transfer_runs (
+  scheduled_transfer_id,
+  occurrence,
+  status,
+  transfer_id,
+  error
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ListScheduledTransferRuns :many
+SELECT * FROM scheduled_transfer_runs
+WHERE scheduled_transfer_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
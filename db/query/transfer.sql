@@ -7,15 +7,143 @@ INSERT INTO transfers (
   $1, $2, $3
 ) RETURNING *;
 
+-- name: CreateCrossCurrencyTransfer :one
+INSERT INTO transfers (
+  from_account_id,
+  to_account_id,
+  amount,
+  to_amount,
+  fx_rate,
+  fx_spread_bps,
+  fx_quote_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
 -- name: GetTransfer :one
 SELECT * FROM transfers
 WHERE id = $1 LIMIT 1;
 
+-- name: GetTransferWithOwners :one
+SELECT
+  t.id,
+  t.from_account_id,
+  t.to_account_id,
+  t.amount,
+  t.created_at,
+  fa.owner AS from_owner,
+  ta.owner AS to_owner,
+  fa.currency,
+  ta.currency AS to_currency,
+  t.to_amount,
+  t.fx_rate,
+  t.fx_spread_bps
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE t.id = $1 LIMIT 1;
+
 -- name: ListTransfers :many
 SELECT * FROM transfers
-WHERE 
-    from_account_id = $1 OR
-    to_account_id = $2
+WHERE
+    from_account_id = sqlc.arg(account_id) OR
+    to_account_id = sqlc.arg(account_id)
 ORDER BY id
-LIMIT $3
-OFFSET $4;
\ No newline at end of file
+LIMIT sqlc.arg(limit_count)
+OFFSET sqlc.arg(offset_count);
+
+-- name: ListUserTransfers :many
+SELECT
+  t.id,
+  t.from_account_id,
+  t.to_account_id,
+  t.amount,
+  t.created_at,
+  fa.owner AS from_owner,
+  ta.owner AS to_owner,
+  fa.currency,
+  ta.currency AS to_currency,
+  t.to_amount,
+  t.fx_rate,
+  t.fx_spread_bps
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE (fa.owner = sqlc.arg(owner) OR ta.owner = sqlc.arg(owner))
+  AND (
+    sqlc.narg(counterparty)::varchar IS NULL
+    OR (fa.owner = sqlc.arg(owner) AND ta.owner = sqlc.narg(counterparty))
+    OR (ta.owner = sqlc.arg(owner) AND fa.owner = sqlc.narg(counterparty))
+  )
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR t.amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR t.amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(currency)::varchar IS NULL OR fa.currency = sqlc.narg(currency) OR ta.currency = sqlc.narg(currency))
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR t.created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR t.created_at < sqlc.narg(to_time))
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT sqlc.arg(limit_count)
+OFFSET sqlc.arg(offset_count);
+
+-- name: ListUserTransfersAfter :many
+SELECT
+  t.id,
+  t.from_account_id,
+  t.to_account_id,
+  t.amount,
+  t.created_at,
+  fa.owner AS from_owner,
+  ta.owner AS to_owner,
+  fa.currency,
+  ta.currency AS to_currency,
+  t.to_amount,
+  t.fx_rate,
+  t.fx_spread_bps
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE (fa.owner = sqlc.arg(owner) OR ta.owner = sqlc.arg(owner))
+  AND (
+    sqlc.narg(counterparty)::varchar IS NULL
+    OR (fa.owner = sqlc.arg(owner) AND ta.owner = sqlc.narg(counterparty))
+    OR (ta.owner = sqlc.arg(owner) AND fa.owner = sqlc.narg(counterparty))
+  )
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR t.amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR t.amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(currency)::varchar IS NULL OR fa.currency = sqlc.narg(currency) OR ta.currency = sqlc.narg(currency))
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR t.created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR t.created_at < sqlc.narg(to_time))
+  AND (t.created_at, t.id) < (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT sqlc.arg(limit_count);
+
+-- name: ListUserTransfersBefore :many
+SELECT
+  t.id,
+  t.from_account_id,
+  t.to_account_id,
+  t.amount,
+  t.created_at,
+  fa.owner AS from_owner,
+  ta.owner AS to_owner,
+  fa.currency,
+  ta.currency AS to_currency,
+  t.to_amount,
+  t.fx_rate,
+  t.fx_spread_bps
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE (fa.owner = sqlc.arg(owner) OR ta.owner = sqlc.arg(owner))
+  AND (
+    sqlc.narg(counterparty)::varchar IS NULL
+    OR (fa.owner = sqlc.arg(owner) AND ta.owner = sqlc.narg(counterparty))
+    OR (ta.owner = sqlc.arg(owner) AND fa.owner = sqlc.narg(counterparty))
+  )
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR t.amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR t.amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(currency)::varchar IS NULL OR fa.currency = sqlc.narg(currency) OR ta.currency = sqlc.narg(currency))
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR t.created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR t.created_at < sqlc.narg(to_time))
+  AND (t.created_at, t.id) > (sqlc.arg(before_created_at)::timestamptz, sqlc.arg(before_id)::bigint)
+ORDER BY t.created_at, t.id
+LIMIT sqlc.arg(limit_count);
\ No newline at end of file
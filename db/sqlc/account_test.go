@@ -15,7 +15,7 @@ func createRandomAccount(t *testing.T) Account {
 	arg := CreateAccountParams{
 		Owner:    user.Username,
 		Balance:  util.RandomMoney(),
-		Currency: util.RandomCurrency(),
+		Currency: randomCurrency(t),
 	}
 
 	account, err := testQueries.CreateAccount(context.Background(), arg)
@@ -72,6 +72,28 @@ func TestUpdateAccount(t *testing.T) {
 	require.WithinDuration(t, acc1.CreatedAt, acc2.CreatedAt, time.Second)
 }
 
+func TestUpdateAccountOverdraftLimit(t *testing.T) {
+	acc1 := createRandomAccount(t)
+	require.Zero(t, acc1.OverdraftLimit)
+
+	arg := UpdateAccountOverdraftLimitParams{
+		ID:             acc1.ID,
+		OverdraftLimit: 100,
+	}
+
+	acc2, err := testQueries.UpdateAccountOverdraftLimit(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.Equal(t, acc1.ID, acc2.ID)
+	require.Equal(t, acc1.Balance, acc2.Balance)
+	require.Equal(t, arg.OverdraftLimit, acc2.OverdraftLimit)
+
+	//negative limits are rejected by the check constraint
+	arg.OverdraftLimit = -1
+	_, err = testQueries.UpdateAccountOverdraftLimit(context.Background(), arg)
+	require.Error(t, err)
+}
+
 func DeleteAccount(t *testing.T) {
 	acc1 := createRandomAccount(t)
 
@@ -86,20 +108,58 @@ func DeleteAccount(t *testing.T) {
 }
 
 func TestMultipleAccounts(t *testing.T) {
+	var lastAccount Account
 	for range 10 {
-		createRandomAccount(t)
+		lastAccount = createRandomAccount(t)
 	}
 
 	arg := ListAccountsParams{
+		Owner:  lastAccount.Owner,
 		Limit:  5,
-		Offset: 5,
+		Offset: 0,
 	}
 
 	accounts, err := testQueries.ListAccounts(context.Background(), arg)
 	require.NoError(t, err)
-	require.Len(t, accounts, 5)
+	require.NotEmpty(t, accounts)
 
 	for _, account := range accounts {
 		require.NotEmpty(t, account)
+		require.Equal(t, lastAccount.Owner, account.Owner)
+	}
+}
+
+func TestListAccountsKeyset(t *testing.T) {
+	user := createRandomUser(t)
+
+	var accounts []Account
+	for _, currency := range []string{"EUR", "INR", "USD"} {
+		account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+			Owner:    user.Username,
+			Balance:  util.RandomMoney(),
+			Currency: currency,
+		})
+		require.NoError(t, err)
+		accounts = append(accounts, account)
 	}
+
+	after, err := testQueries.ListAccountsAfter(context.Background(), ListAccountsAfterParams{
+		Owner:      user.Username,
+		AfterID:    accounts[0].ID,
+		LimitCount: 5,
+	})
+	require.NoError(t, err)
+	require.Len(t, after, 2)
+	require.Equal(t, accounts[1].ID, after[0].ID)
+	require.Equal(t, accounts[2].ID, after[1].ID)
+
+	//rows before the cursor come back closest first
+	before, err := testQueries.ListAccountsBefore(context.Background(), ListAccountsBeforeParams{
+		Owner:      user.Username,
+		BeforeID:   accounts[2].ID,
+		LimitCount: 1,
+	})
+	require.NoError(t, err)
+	require.Len(t, before, 1)
+	require.Equal(t, accounts[1].ID, before[0].ID)
 }
@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// SystemAccountOwner owns the cash account of every currency. Deposits and
+// withdrawals move money between a customer account and that cash account
+// so that the entries of the ledger always sum to zero.
+const SystemAccountOwner = "system"
+
+// DepositTxParams contains the input parameters of the deposit transaction
+type DepositTxParams struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+}
+
+// WithdrawTxParams contains the input parameters of the withdraw transaction
+type WithdrawTxParams struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+}
+
+// CashTxResult is the result of the deposit and withdraw transactions
+type CashTxResult struct {
+	Account     Account `json:"account"`
+	Entry       Entry   `json:"entry"`
+	CashAccount Account `json:"-"`
+	CashEntry   Entry   `json:"-"`
+}
+
+// DepositTx adds money to an account, taking it from the cash account of
+// the same currency
+func (store *SqlStore) DepositTx(ctx context.Context, arg DepositTxParams) (CashTxResult, error) {
+	return store.cashTx(ctx, arg.AccountID, arg.Amount)
+}
+
+// WithdrawTx takes money out of an account into the cash account of the same
+// currency. It fails with ErrInsufficientFunds when the account would go
+// below its overdraft limit.
+func (store *SqlStore) WithdrawTx(ctx context.Context, arg WithdrawTxParams) (CashTxResult, error) {
+	return store.cashTx(ctx, arg.AccountID, -arg.Amount)
+}
+
+// cashTx moves amount from the cash account into accountID, a negative
+// amount moves it the other way
+func (store *SqlStore) cashTx(ctx context.Context, accountID int64, amount int64) (CashTxResult, error) {
+	var result CashTxResult
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		account, err := q.GetAccount(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		cashAccount, err := q.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+			Owner:    SystemAccountOwner,
+			Currency: account.Currency,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot get %s cash account: %w", account.Currency, err)
+		}
+
+		kind, description := JournalKindDeposit, "cash deposit"
+		if amount < 0 {
+			kind, description = JournalKindWithdrawal, "cash withdrawal"
+		}
+
+		journal, err := postJournal(ctx, q, PostJournalTxParams{
+			Kind:        kind,
+			Description: description,
+			Postings: []Posting{
+				{AccountID: account.ID, Amount: amount},
+				{AccountID: cashAccount.ID, Amount: -amount},
+			},
+		}, sql.NullInt64{})
+		if err != nil {
+			return err
+		}
+
+		result.Entry, result.CashEntry = journal.Entries[0], journal.Entries[1]
+		result.Account, result.CashAccount = journal.account(account.ID), journal.account(cashAccount.ID)
+		return nil
+	})
+
+	return result, err
+}
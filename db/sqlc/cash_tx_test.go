@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func getCashAccount(t *testing.T, currency string) Account {
+	account, err := testQueries.GetAccountByOwnerAndCurrency(context.Background(), GetAccountByOwnerAndCurrencyParams{
+		Owner:    SystemAccountOwner,
+		Currency: currency,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func TestDepositTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	cashAccount := getCashAccount(t, account.Currency)
+	amount := int64(10)
+
+	result, err := store.DepositTx(context.Background(), DepositTxParams{
+		AccountID: account.ID,
+		Amount:    amount,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, account.ID, result.Entry.AccountID)
+	require.Equal(t, amount, result.Entry.Amount)
+	require.Equal(t, cashAccount.ID, result.CashEntry.AccountID)
+	require.Equal(t, -amount, result.CashEntry.Amount)
+
+	require.Equal(t, account.ID, result.Account.ID)
+	require.Equal(t, account.Balance+amount, result.Account.Balance)
+	require.Equal(t, cashAccount.ID, result.CashAccount.ID)
+}
+
+func TestWithdrawTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	amount := int64(10)
+
+	result, err := store.WithdrawTx(context.Background(), WithdrawTxParams{
+		AccountID: account.ID,
+		Amount:    amount,
+	})
+	require.NoError(t, err)
+	require.Equal(t, -amount, result.Entry.Amount)
+	require.Equal(t, amount, result.CashEntry.Amount)
+	require.Equal(t, account.Balance-amount, result.Account.Balance)
+
+	//withdrawing more than is left is rejected and nothing is written
+	_, err = store.WithdrawTx(context.Background(), WithdrawTxParams{
+		AccountID: account.ID,
+		Amount:    result.Account.Balance + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	updatedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.Account.Balance, updatedAccount.Balance)
+}
+
+func TestCashTxConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	//run deposits and withdrawals concurrently, each one moves money through a cash account
+	n := 20
+	amount := int64(10)
+
+	errs := make(chan error)
+
+	for i := range n {
+		ctx := context.Background()
+		go func() {
+			var err error
+			if i%2 == 0 {
+				_, err = store.DepositTx(ctx, DepositTxParams{AccountID: account1.ID, Amount: amount})
+			} else {
+				_, err = store.WithdrawTx(ctx, WithdrawTxParams{AccountID: account2.ID, Amount: amount})
+			}
+			errs <- err
+		}()
+	}
+
+	for range n {
+		err := <-errs
+		require.NoError(t, err)
+	}
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance+int64(n/2)*amount, updatedAccount1.Balance)
+
+	updatedAccount2, err := testQueries.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance-int64(n/2)*amount, updatedAccount2.Balance)
+}
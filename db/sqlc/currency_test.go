@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/gurukanth/simplebank/util"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+// randomCurrency returns one of the currencies the migrations enable
+func randomCurrency(t *testing.T) string {
+	currencies, err := testQueries.ListCurrencies(context.Background())
+	require.NoError(t, err)
+
+	var enabled []string
+	for _, currency := range currencies {
+		if currency.Enabled {
+			enabled = append(enabled, currency.Code)
+		}
+	}
+	require.NotEmpty(t, enabled)
+	return enabled[util.RandomInt(0, int64(len(enabled)-1))]
+}
+
+func TestGetCurrency(t *testing.T) {
+	currency, err := testQueries.GetCurrency(context.Background(), "USD")
+	require.NoError(t, err)
+	require.Equal(t, "USD", currency.Code)
+	require.Equal(t, int32(840), currency.NumericCode)
+	require.Equal(t, int16(2), currency.Exponent)
+	require.True(t, currency.Enabled)
+
+	_, err = testQueries.GetCurrency(context.Background(), "XYZ")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestListCurrencies(t *testing.T) {
+	currencies, err := testQueries.ListCurrencies(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, currencies)
+
+	for i := 1; i < len(currencies); i++ {
+		require.Less(t, currencies[i-1].Code, currencies[i].Code)
+	}
+}
+
+func TestSystemCashAccountPerCurrency(t *testing.T) {
+	currencies, err := testQueries.ListCurrencies(context.Background())
+	require.NoError(t, err)
+
+	// disabled currencies too, so that enabling one needs no new account
+	for _, currency := range currencies {
+		account := getCashAccount(t, currency.Code)
+		require.Equal(t, SystemAccountOwner, account.Owner)
+	}
+}
+
+func TestUpdateCurrencyEnabled(t *testing.T) {
+	currency, err := testQueries.UpdateCurrencyEnabled(context.Background(), UpdateCurrencyEnabledParams{
+		Code:    "KWD",
+		Enabled: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "KWD", currency.Code)
+	require.Equal(t, int16(3), currency.Exponent)
+	require.True(t, currency.Enabled)
+
+	currency, err = testQueries.UpdateCurrencyEnabled(context.Background(), UpdateCurrencyEnabledParams{
+		Code:    "KWD",
+		Enabled: false,
+	})
+	require.NoError(t, err)
+	require.False(t, currency.Enabled)
+
+	_, err = testQueries.UpdateCurrencyEnabled(context.Background(), UpdateCurrencyEnabledParams{
+		Code:    "XYZ",
+		Enabled: true,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestCreateAccountUnknownCurrency(t *testing.T) {
+	user := createRandomUser(t)
+
+	_, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  util.RandomMoney(),
+		Currency: "XYZ",
+	})
+
+	var pqErr *pq.Error
+	require.True(t, errors.As(err, &pqErr))
+	require.Equal(t, pq.ErrorCode("23503"), pqErr.Code)
+}
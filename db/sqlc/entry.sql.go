@@ -7,36 +7,49 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  transfer_id,
+  journal_id
 ) VALUES (
-  $1, $2
-) RETURNING id, account_id, amount, created_at
+  $1, $2, $3, $4
+) RETURNING id, account_id, amount, created_at, transfer_id, journal_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64
-	Amount    int64
+	AccountID  int64
+	Amount     int64
+	TransferID sql.NullInt64
+	JournalID  int64
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry,
+		arg.AccountID,
+		arg.Amount,
+		arg.TransferID,
+		arg.JournalID,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.JournalID,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, journal_id FROM entries
 WHERE id = $1 LIMIT 1
 `
 
@@ -48,12 +61,265 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.JournalID,
 	)
 	return i, err
 }
 
+const listAccountStatement = `-- name: ListAccountStatement :many
+SELECT id, account_id, amount, transfer_id, created_at, running_balance
+FROM (
+  SELECT
+    e.id,
+    e.account_id,
+    e.amount,
+    e.transfer_id,
+    e.created_at,
+    (a.balance - SUM(e.amount) OVER (ORDER BY e.created_at DESC, e.id DESC) + e.amount)::bigint AS running_balance
+  FROM entries e
+  JOIN accounts a ON a.id = e.account_id
+  WHERE e.account_id = $1
+    AND e.created_at >= $2
+) AS statement
+WHERE created_at < $3
+  AND (
+    $4::varchar = ''
+    OR ($4 = 'credit' AND amount > 0)
+    OR ($4 = 'debit' AND amount < 0)
+  )
+ORDER BY created_at, id
+LIMIT $5
+OFFSET $6
+`
+
+type ListAccountStatementParams struct {
+	AccountID   int64
+	FromTime    time.Time
+	ToTime      time.Time
+	Direction   string
+	LimitCount  int32
+	OffsetCount int32
+}
+
+type ListAccountStatementRow struct {
+	ID             int64
+	AccountID      int64
+	Amount         int64
+	TransferID     sql.NullInt64
+	CreatedAt      time.Time
+	RunningBalance int64
+}
+
+// running_balance is the account balance less every entry made after the
+// row, so the window reads all entries of the account from from_time up to
+// now, not only those of the page. The cost grows with how far back from_time
+// is, and the (account_id, created_at) index bounds it to that range.
+func (q *Queries) ListAccountStatement(ctx context.Context, arg ListAccountStatementParams) ([]ListAccountStatementRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountStatement,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Direction,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAccountStatementRow
+	for rows.Next() {
+		var i ListAccountStatementRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.TransferID,
+			&i.CreatedAt,
+			&i.RunningBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountStatementAfter = `-- name: ListAccountStatementAfter :many
+SELECT id, account_id, amount, transfer_id, created_at, running_balance
+FROM (
+  SELECT
+    e.id,
+    e.account_id,
+    e.amount,
+    e.transfer_id,
+    e.created_at,
+    (a.balance - SUM(e.amount) OVER (ORDER BY e.created_at DESC, e.id DESC) + e.amount)::bigint AS running_balance
+  FROM entries e
+  JOIN accounts a ON a.id = e.account_id
+  WHERE e.account_id = $1
+    AND e.created_at >= $2
+) AS statement
+WHERE created_at < $3
+  AND (
+    $4::varchar = ''
+    OR ($4 = 'credit' AND amount > 0)
+    OR ($4 = 'debit' AND amount < 0)
+  )
+  AND (created_at, id) > ($5::timestamptz, $6::bigint)
+ORDER BY created_at, id
+LIMIT $7
+`
+
+type ListAccountStatementAfterParams struct {
+	AccountID      int64
+	FromTime       time.Time
+	ToTime         time.Time
+	Direction      string
+	AfterCreatedAt time.Time
+	AfterID        int64
+	LimitCount     int32
+}
+
+type ListAccountStatementAfterRow struct {
+	ID             int64
+	AccountID      int64
+	Amount         int64
+	TransferID     sql.NullInt64
+	CreatedAt      time.Time
+	RunningBalance int64
+}
+
+func (q *Queries) ListAccountStatementAfter(ctx context.Context, arg ListAccountStatementAfterParams) ([]ListAccountStatementAfterRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountStatementAfter,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Direction,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAccountStatementAfterRow
+	for rows.Next() {
+		var i ListAccountStatementAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.TransferID,
+			&i.CreatedAt,
+			&i.RunningBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountStatementBefore = `-- name: ListAccountStatementBefore :many
+SELECT id, account_id, amount, transfer_id, created_at, running_balance
+FROM (
+  SELECT
+    e.id,
+    e.account_id,
+    e.amount,
+    e.transfer_id,
+    e.created_at,
+    (a.balance - SUM(e.amount) OVER (ORDER BY e.created_at DESC, e.id DESC) + e.amount)::bigint AS running_balance
+  FROM entries e
+  JOIN accounts a ON a.id = e.account_id
+  WHERE e.account_id = $1
+    AND e.created_at >= $2
+) AS statement
+WHERE created_at < $3
+  AND (
+    $4::varchar = ''
+    OR ($4 = 'credit' AND amount > 0)
+    OR ($4 = 'debit' AND amount < 0)
+  )
+  AND (created_at, id) < ($5::timestamptz, $6::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT $7
+`
+
+type ListAccountStatementBeforeParams struct {
+	AccountID       int64
+	FromTime        time.Time
+	ToTime          time.Time
+	Direction       string
+	BeforeCreatedAt time.Time
+	BeforeID        int64
+	LimitCount      int32
+}
+
+type ListAccountStatementBeforeRow struct {
+	ID             int64
+	AccountID      int64
+	Amount         int64
+	TransferID     sql.NullInt64
+	CreatedAt      time.Time
+	RunningBalance int64
+}
+
+func (q *Queries) ListAccountStatementBefore(ctx context.Context, arg ListAccountStatementBeforeParams) ([]ListAccountStatementBeforeRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountStatementBefore,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Direction,
+		arg.BeforeCreatedAt,
+		arg.BeforeID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAccountStatementBeforeRow
+	for rows.Next() {
+		var i ListAccountStatementBeforeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.TransferID,
+			&i.CreatedAt,
+			&i.RunningBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, journal_id FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
@@ -80,6 +346,44 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+			&i.JournalID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJournalEntries = `-- name: ListJournalEntries :many
+SELECT id, account_id, amount, created_at, transfer_id, journal_id FROM entries
+WHERE journal_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListJournalEntries(ctx context.Context, journalID int64) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listJournalEntries, journalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Entry
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.JournalID,
 		); err != nil {
 			return nil, err
 		}
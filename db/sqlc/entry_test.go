@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAccountStatement(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1, account2 := createRandomAccountPair(t)
+
+	start := time.Now().Add(-time.Minute)
+
+	_, err := store.DepositTx(context.Background(), DepositTxParams{AccountID: account1.ID, Amount: 100})
+	require.NoError(t, err)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountId: account1.ID,
+		ToAccountId:   account2.ID,
+		Amount:        30,
+	})
+	require.NoError(t, err)
+
+	_, err = store.WithdrawTx(context.Background(), WithdrawTxParams{AccountID: account1.ID, Amount: 20})
+	require.NoError(t, err)
+
+	arg := ListAccountStatementParams{
+		AccountID:   account1.ID,
+		FromTime:    start,
+		ToTime:      time.Now().Add(time.Minute),
+		LimitCount:  10,
+		OffsetCount: 0,
+	}
+
+	rows, err := testQueries.ListAccountStatement(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	require.Equal(t, []int64{100, -30, -20}, []int64{rows[0].Amount, rows[1].Amount, rows[2].Amount})
+	require.Equal(t, account1.Balance+100, rows[0].RunningBalance)
+	require.Equal(t, account1.Balance+70, rows[1].RunningBalance)
+	require.Equal(t, account1.Balance+50, rows[2].RunningBalance)
+
+	require.False(t, rows[0].TransferID.Valid)
+	require.True(t, rows[1].TransferID.Valid)
+	require.Equal(t, transfer.Transfer.ID, rows[1].TransferID.Int64)
+
+	//only debits
+	arg.Direction = "debit"
+	rows, err = testQueries.ListAccountStatement(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	for _, row := range rows {
+		require.Negative(t, row.Amount)
+	}
+
+	//running balance is kept when paging
+	arg.Direction = ""
+	arg.LimitCount = 1
+	arg.OffsetCount = 2
+	rows, err = testQueries.ListAccountStatement(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, account1.Balance+50, rows[0].RunningBalance)
+
+	//nothing outside of the time range
+	arg.OffsetCount = 0
+	arg.ToTime = start
+	rows, err = testQueries.ListAccountStatement(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, rows)
+}
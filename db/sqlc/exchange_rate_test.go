@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLatestExchangeRate(t *testing.T) {
+	for _, rate := range []string{"1.05", "1.0842"} {
+		_, err := testQueries.CreateExchangeRate(context.Background(), CreateExchangeRateParams{
+			BaseCurrency:  "EUR",
+			QuoteCurrency: "INR",
+			Rate:          rate,
+		})
+		require.NoError(t, err)
+	}
+
+	latest, err := testQueries.GetLatestExchangeRate(context.Background(), GetLatestExchangeRateParams{
+		BaseCurrency:  "EUR",
+		QuoteCurrency: "INR",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "1.0842000000", latest.Rate)
+
+	rates, err := testQueries.ListLatestExchangeRates(context.Background())
+	require.NoError(t, err)
+
+	var found int
+	for _, rate := range rates {
+		if rate.BaseCurrency == "EUR" && rate.QuoteCurrency == "INR" {
+			require.Equal(t, latest.ID, rate.ID)
+			found++
+		}
+	}
+	require.Equal(t, 1, found)
+}
+
+func TestCreateExchangeRateInvalid(t *testing.T) {
+	_, err := testQueries.CreateExchangeRate(context.Background(), CreateExchangeRateParams{
+		BaseCurrency:  "USD",
+		QuoteCurrency: "USD",
+		Rate:          "1",
+	})
+	require.Error(t, err)
+
+	_, err = testQueries.CreateExchangeRate(context.Background(), CreateExchangeRateParams{
+		BaseCurrency:  "USD",
+		QuoteCurrency: "EUR",
+		Rate:          "0",
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/gurukanth/simplebank/fx"
+)
+
+// FxHouseOwner owns one account per currency that cross-currency transfers
+// pass through. The source amount is paid into the house account of the
+// source currency and the converted amount out of the house account of the
+// target currency, so the entries of each currency still sum to zero.
+const FxHouseOwner = "fxhouse"
+
+// ErrFxQuoteUnavailable is returned by CrossCurrencyTransferTx when the quote
+// does not exist, belongs to another user, has expired or was already used
+var ErrFxQuoteUnavailable = errors.New("fx quote not found, expired or already used")
+
+// ErrFxQuoteMismatch is returned by CrossCurrencyTransferTx when the accounts
+// are not in the currencies the quote was given for
+var ErrFxQuoteMismatch = errors.New("accounts do not match the currencies of the fx quote")
+
+// ErrFxAmountTooSmall is returned by CrossCurrencyTransferTx when the amount
+// converts to less than one minor unit of the target currency
+var ErrFxAmountTooSmall = errors.New("amount is too small to convert")
+
+// CrossCurrencyTransferTxParams contains the input parameters of the
+// cross-currency transfer transaction. Amount is in the currency of the from
+// account and Username must be the user the quote was given to.
+type CrossCurrencyTransferTxParams struct {
+	TransferTxParams
+	QuoteID uuid.UUID `json:"quote_id"`
+}
+
+// CrossCurrencyTransferTx moves money between accounts in different
+// currencies at the rate locked by a quote. The quote is used up by the
+// transfer, and is left unused when the transfer fails.
+func (store *SqlStore) CrossCurrencyTransferTx(ctx context.Context, arg CrossCurrencyTransferTxParams) (TransferTxResult, error) {
+	return store.idempotentTransferTx(ctx, arg.TransferTxParams, func(q *Queries) (TransferTxResult, error) {
+		var result TransferTxResult
+
+		quote, err := q.UseFxQuote(ctx, UseFxQuoteParams{
+			ID:       arg.QuoteID,
+			Username: arg.Username,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return result, ErrFxQuoteUnavailable
+		}
+		if err != nil {
+			return result, err
+		}
+
+		fromAccount, err := q.GetAccount(ctx, arg.FromAccountId)
+		if err != nil {
+			return result, err
+		}
+		toAccount, err := q.GetAccount(ctx, arg.ToAccountId)
+		if err != nil {
+			return result, err
+		}
+		if fromAccount.Currency != quote.FromCurrency || toAccount.Currency != quote.ToCurrency {
+			return result, ErrFxQuoteMismatch
+		}
+
+		toAmount, err := convertQuoted(ctx, q, quote, arg.Amount)
+		if err != nil {
+			return result, err
+		}
+
+		fromHouse, err := q.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+			Owner:    FxHouseOwner,
+			Currency: quote.FromCurrency,
+		})
+		if err != nil {
+			return result, fmt.Errorf("cannot get %s fx house account: %w", quote.FromCurrency, err)
+		}
+		toHouse, err := q.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+			Owner:    FxHouseOwner,
+			Currency: quote.ToCurrency,
+		})
+		if err != nil {
+			return result, fmt.Errorf("cannot get %s fx house account: %w", quote.ToCurrency, err)
+		}
+
+		result.Transfer, err = q.CreateCrossCurrencyTransfer(ctx, CreateCrossCurrencyTransferParams{
+			FromAccountID: arg.FromAccountId,
+			ToAccountID:   arg.ToAccountId,
+			Amount:        arg.Amount,
+			ToAmount:      sql.NullInt64{Int64: toAmount, Valid: true},
+			FxRate:        sql.NullString{String: quote.Rate, Valid: true},
+			FxSpreadBps:   sql.NullInt32{Int32: quote.SpreadBps, Valid: true},
+			FxQuoteID:     uuid.NullUUID{UUID: quote.ID, Valid: true},
+		})
+		if err != nil {
+			return result, err
+		}
+
+		journal, err := postJournal(ctx, q, PostJournalTxParams{
+			Kind:        JournalKindFxTransfer,
+			Description: fmt.Sprintf("%s to %s at %s less %d bps", quote.FromCurrency, quote.ToCurrency, quote.Rate, quote.SpreadBps),
+			Postings: []Posting{
+				{AccountID: fromAccount.ID, Amount: -arg.Amount},
+				{AccountID: fromHouse.ID, Amount: arg.Amount},
+				{AccountID: toHouse.ID, Amount: -toAmount},
+				{AccountID: toAccount.ID, Amount: toAmount},
+			},
+		}, sql.NullInt64{Int64: result.Transfer.ID, Valid: true})
+		if err != nil {
+			return result, err
+		}
+
+		result.FromEntry, result.ToEntry = journal.Entries[0], journal.Entries[3]
+		result.FromAccount, result.ToAccount = journal.account(fromAccount.ID), journal.account(toAccount.ID)
+		return result, nil
+	})
+}
+
+// convertQuoted converts amount from the source to the target currency of
+// quote, in minor units of each
+func convertQuoted(ctx context.Context, q *Queries, quote FxQuote, amount int64) (int64, error) {
+	fromCurrency, err := q.GetCurrency(ctx, quote.FromCurrency)
+	if err != nil {
+		return 0, err
+	}
+	toCurrency, err := q.GetCurrency(ctx, quote.ToCurrency)
+	if err != nil {
+		return 0, err
+	}
+
+	rate, err := fx.ParseRate(quote.Rate)
+	if err != nil {
+		return 0, fmt.Errorf("fx quote %s: %w", quote.ID, err)
+	}
+
+	toAmount, err := fx.Convert(amount, rate, quote.SpreadBps, fromCurrency.Exponent, toCurrency.Exponent)
+	if err != nil {
+		return 0, err
+	}
+	if toAmount <= 0 {
+		return 0, ErrFxAmountTooSmall
+	}
+	return toAmount, nil
+}
@@ -0,0 +1,153 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gurukanth/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomFxQuote(t *testing.T, username, from, to, rate string, ttl time.Duration) FxQuote {
+	arg := CreateFxQuoteParams{
+		ID:           uuid.New(),
+		Username:     username,
+		FromCurrency: from,
+		ToCurrency:   to,
+		Rate:         rate,
+		SpreadBps:    50,
+		ExpiresAt:    time.Now().Add(ttl),
+	}
+
+	quote, err := testQueries.CreateFxQuote(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ID, quote.ID)
+	require.False(t, quote.UsedAt.Valid)
+	return quote
+}
+
+func createAccountIn(t *testing.T, owner, currency string, balance int64) Account {
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    owner,
+		Balance:  balance,
+		Currency: currency,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func getFxHouseAccount(t *testing.T, currency string) Account {
+	account, err := testQueries.GetAccountByOwnerAndCurrency(context.Background(), GetAccountByOwnerAndCurrencyParams{
+		Owner:    FxHouseOwner,
+		Currency: currency,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func TestCrossCurrencyTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	fromAccount := createAccountIn(t, user.Username, "EUR", 10000)
+	toAccount := createAccountIn(t, createRandomUser(t).Username, "USD", 0)
+	eurHouse := getFxHouseAccount(t, "EUR")
+	usdHouse := getFxHouseAccount(t, "USD")
+
+	quote := createRandomFxQuote(t, user.Username, "EUR", "USD", "1.1", time.Minute)
+
+	result, err := store.CrossCurrencyTransferTx(context.Background(), CrossCurrencyTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountId: fromAccount.ID,
+			ToAccountId:   toAccount.ID,
+			Amount:        1000,
+			Username:      user.Username,
+		},
+		QuoteID: quote.ID,
+	})
+	require.NoError(t, err)
+
+	// 10.00 EUR at 1.1 less 50 bps is 10.945 USD, rounded down
+	require.Equal(t, int64(1000), result.Transfer.Amount)
+	require.Equal(t, sql.NullInt64{Int64: 1094, Valid: true}, result.Transfer.ToAmount)
+	require.Equal(t, "1.1000000000", result.Transfer.FxRate.String)
+	require.Equal(t, int32(50), result.Transfer.FxSpreadBps.Int32)
+	require.Equal(t, quote.ID, result.Transfer.FxQuoteID.UUID)
+
+	require.Equal(t, int64(-1000), result.FromEntry.Amount)
+	require.Equal(t, int64(1094), result.ToEntry.Amount)
+	require.Equal(t, int64(9000), result.FromAccount.Balance)
+	require.Equal(t, int64(1094), result.ToAccount.Balance)
+
+	// the house accounts take the other side in each currency
+	updatedEurHouse := getFxHouseAccount(t, "EUR")
+	updatedUsdHouse := getFxHouseAccount(t, "USD")
+	require.Equal(t, eurHouse.Balance+1000, updatedEurHouse.Balance)
+	require.Equal(t, usdHouse.Balance-1094, updatedUsdHouse.Balance)
+
+	// the receiver finds the transfer by the currency it was credited in
+	rows, err := testQueries.ListUserTransfers(context.Background(), ListUserTransfersParams{
+		Owner:      toAccount.Owner,
+		Currency:   sql.NullString{String: "USD", Valid: true},
+		LimitCount: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, "EUR", rows[0].Currency)
+	require.Equal(t, "USD", rows[0].ToCurrency)
+	require.Equal(t, result.Transfer.ToAmount, rows[0].ToAmount)
+	require.Equal(t, result.Transfer.FxRate, rows[0].FxRate)
+	require.Equal(t, result.Transfer.FxSpreadBps, rows[0].FxSpreadBps)
+
+	// a quote is good for a single transfer
+	_, err = store.CrossCurrencyTransferTx(context.Background(), CrossCurrencyTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountId: fromAccount.ID,
+			ToAccountId:   toAccount.ID,
+			Amount:        1000,
+			Username:      user.Username,
+		},
+		QuoteID: quote.ID,
+	})
+	require.ErrorIs(t, err, ErrFxQuoteUnavailable)
+}
+
+func TestCrossCurrencyTransferTxRejected(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	fromAccount := createAccountIn(t, user.Username, "EUR", 100)
+	toAccount := createAccountIn(t, createRandomUser(t).Username, "USD", 0)
+	inrAccount := createAccountIn(t, createRandomUser(t).Username, "INR", 0)
+
+	transfer := func(quoteID uuid.UUID, username string, to Account, amount int64) error {
+		_, err := store.CrossCurrencyTransferTx(context.Background(), CrossCurrencyTransferTxParams{
+			TransferTxParams: TransferTxParams{
+				FromAccountId: fromAccount.ID,
+				ToAccountId:   to.ID,
+				Amount:        amount,
+				Username:      username,
+			},
+			QuoteID: quoteID,
+		})
+		return err
+	}
+
+	expired := createRandomFxQuote(t, user.Username, "EUR", "USD", "1.1", -time.Second)
+	require.ErrorIs(t, transfer(expired.ID, user.Username, toAccount, 10), ErrFxQuoteUnavailable)
+
+	quote := createRandomFxQuote(t, user.Username, "EUR", "USD", "1.1", time.Minute)
+	require.ErrorIs(t, transfer(quote.ID, util.RandomOwner(), toAccount, 10), ErrFxQuoteUnavailable)
+	require.ErrorIs(t, transfer(quote.ID, user.Username, inrAccount, 10), ErrFxQuoteMismatch)
+	require.ErrorIs(t, transfer(quote.ID, user.Username, toAccount, 1000), ErrInsufficientFunds)
+
+	// failed transfers leave the quote unused
+	require.NoError(t, transfer(quote.ID, user.Username, toAccount, 10))
+
+	updatedAccount, err := testQueries.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(90), updatedAccount.Balance)
+}
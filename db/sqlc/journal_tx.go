@@ -0,0 +1,172 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/lib/pq"
+)
+
+// Kinds of the journals written by the store itself
+const (
+	JournalKindTransfer   = "transfer"
+	JournalKindFxTransfer = "fx_transfer"
+	JournalKindDeposit    = "deposit"
+	JournalKindWithdrawal = "withdrawal"
+)
+
+// ErrInvalidJournal is returned by PostJournalTx when the postings cannot
+// form a journal at all
+var ErrInvalidJournal = errors.New("invalid journal")
+
+// ErrUnbalancedJournal is returned by PostJournalTx when the postings do not
+// sum to zero in every currency
+var ErrUnbalancedJournal = errors.New("journal postings do not sum to zero in every currency")
+
+// Posting is a single leg of a journal: money into (positive Amount) or out
+// of (negative Amount) an account, in the currency of the account
+type Posting struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+}
+
+// PostJournalTxParams contains the input parameters of the journal transaction
+type PostJournalTxParams struct {
+	Kind        string    `json:"kind"`
+	Description string    `json:"description"`
+	Postings    []Posting `json:"postings"`
+}
+
+// PostJournalTxResult is the result of the journal transaction
+type PostJournalTxResult struct {
+	Journal JournalTransaction `json:"journal"`
+	// Entries holds one entry per posting, in the order of the postings
+	Entries []Entry `json:"entries"`
+	// Accounts holds every account the journal touched, by ascending id
+	Accounts []Account `json:"accounts"`
+}
+
+// account returns the updated account with the given id
+func (result PostJournalTxResult) account(id int64) Account {
+	for _, account := range result.Accounts {
+		if account.ID == id {
+			return account
+		}
+	}
+	return Account{}
+}
+
+// PostJournalTx writes a journal of any number of postings atomically, such
+// as a fee taken alongside a payment or a payment split between accounts.
+// The postings must sum to zero in each currency, and no customer account
+// may end up below its overdraft limit.
+func (store *SqlStore) PostJournalTx(ctx context.Context, arg PostJournalTxParams) (PostJournalTxResult, error) {
+	var result PostJournalTxResult
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		var err error
+		result, err = postJournal(ctx, q, arg, sql.NullInt64{})
+		return err
+	})
+	if isJournalBalanceViolation(err) {
+		return result, fmt.Errorf("%w: %w", ErrUnbalancedJournal, err)
+	}
+
+	return result, err
+}
+
+// postJournal writes a journal and its entries and applies the postings to
+// the account balances, linking everything to transferID when it is set
+func postJournal(ctx context.Context, q *Queries, arg PostJournalTxParams, transferID sql.NullInt64) (PostJournalTxResult, error) {
+	var result PostJournalTxResult
+
+	if len(arg.Postings) < 2 {
+		return result, fmt.Errorf("%w: a journal needs at least two postings", ErrInvalidJournal)
+	}
+	moves := make(map[int64]int64, len(arg.Postings))
+	for _, posting := range arg.Postings {
+		if posting.Amount == 0 {
+			return result, fmt.Errorf("%w: posting to account %d has no amount", ErrInvalidJournal, posting.AccountID)
+		}
+		moves[posting.AccountID] += posting.Amount
+	}
+
+	var err error
+	result.Journal, err = q.CreateJournalTransaction(ctx, CreateJournalTransactionParams{
+		Kind:        arg.Kind,
+		Description: arg.Description,
+		TransferID:  transferID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Entries = make([]Entry, len(arg.Postings))
+	for i, posting := range arg.Postings {
+		result.Entries[i], err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  posting.AccountID,
+			Amount:     posting.Amount,
+			TransferID: transferID,
+			JournalID:  result.Journal.ID,
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// AddAccountBalance locks the account rows, so they are updated in id
+	// order to keep concurrent journals over the same accounts from
+	// deadlocking
+	accountIDs := make([]int64, 0, len(moves))
+	for accountID := range moves {
+		accountIDs = append(accountIDs, accountID)
+	}
+	slices.Sort(accountIDs)
+
+	result.Accounts = make([]Account, len(accountIDs))
+	for i, accountID := range accountIDs {
+		result.Accounts[i], err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     accountID,
+			Amount: moves[accountID],
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	sums := make(map[string]int64)
+	for _, account := range result.Accounts {
+		sums[account.Currency] += moves[account.ID]
+	}
+	for currency, sum := range sums {
+		if sum != 0 {
+			return result, fmt.Errorf("%w: %s postings sum to %d", ErrUnbalancedJournal, currency, sum)
+		}
+	}
+
+	// the account rows are locked, so these checks cannot race with other
+	// journals taking money out of the same accounts
+	for _, account := range result.Accounts {
+		if moves[account.ID] < 0 && !isHouseAccount(account) && account.Balance < -account.OverdraftLimit {
+			return result, ErrInsufficientFunds
+		}
+	}
+
+	return result, nil
+}
+
+// isHouseAccount reports whether the bank owns the account. House accounts
+// stand for money outside the ledger, so their balance may be negative.
+func isHouseAccount(account Account) bool {
+	return account.Owner == SystemAccountOwner || account.Owner == FxHouseOwner
+}
+
+// isJournalBalanceViolation reports whether err was raised by the deferred
+// journal_balanced constraint trigger when the transaction committed
+func isJournalBalanceViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == checkViolation && pqErr.Constraint == "journal_balanced"
+}
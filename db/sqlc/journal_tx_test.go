@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostJournalTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	payer := createAccountIn(t, createRandomUser(t).Username, "USD", 1000)
+	payee1 := createAccountIn(t, createRandomUser(t).Username, "USD", 0)
+	payee2 := createAccountIn(t, createRandomUser(t).Username, "USD", 0)
+
+	result, err := store.PostJournalTx(context.Background(), PostJournalTxParams{
+		Kind:        "split",
+		Description: "dinner",
+		Postings: []Posting{
+			{AccountID: payer.ID, Amount: -300},
+			{AccountID: payee1.ID, Amount: 200},
+			{AccountID: payee2.ID, Amount: 100},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "split", result.Journal.Kind)
+	require.False(t, result.Journal.TransferID.Valid)
+
+	require.Len(t, result.Entries, 3)
+	for _, entry := range result.Entries {
+		require.Equal(t, result.Journal.ID, entry.JournalID)
+	}
+	require.Equal(t, int64(700), result.account(payer.ID).Balance)
+	require.Equal(t, int64(200), result.account(payee1.ID).Balance)
+	require.Equal(t, int64(100), result.account(payee2.ID).Balance)
+
+	entries, err := testQueries.ListJournalEntries(context.Background(), result.Journal.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.Entries, entries)
+}
+
+func TestPostJournalTxRejected(t *testing.T) {
+	store := NewStore(testDB)
+
+	usd := createAccountIn(t, createRandomUser(t).Username, "USD", 100)
+	usd2 := createAccountIn(t, createRandomUser(t).Username, "USD", 0)
+	eur := createAccountIn(t, createRandomUser(t).Username, "EUR", 0)
+
+	testCases := []struct {
+		name     string
+		postings []Posting
+		err      error
+	}{
+		{
+			name:     "SinglePosting",
+			postings: []Posting{{AccountID: usd.ID, Amount: -10}},
+			err:      ErrInvalidJournal,
+		},
+		{
+			name:     "ZeroAmount",
+			postings: []Posting{{AccountID: usd.ID, Amount: 0}, {AccountID: usd2.ID, Amount: 0}},
+			err:      ErrInvalidJournal,
+		},
+		{
+			name:     "Unbalanced",
+			postings: []Posting{{AccountID: usd.ID, Amount: -10}, {AccountID: usd2.ID, Amount: 5}},
+			err:      ErrUnbalancedJournal,
+		},
+		{
+			// the amounts sum to zero, but not within each currency
+			name:     "MixedCurrencies",
+			postings: []Posting{{AccountID: usd.ID, Amount: -10}, {AccountID: eur.ID, Amount: 10}},
+			err:      ErrUnbalancedJournal,
+		},
+		{
+			name:     "InsufficientFunds",
+			postings: []Posting{{AccountID: usd.ID, Amount: -101}, {AccountID: usd2.ID, Amount: 101}},
+			err:      ErrInsufficientFunds,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := store.PostJournalTx(context.Background(), PostJournalTxParams{
+				Kind:        "adjustment",
+				Description: tc.name,
+				Postings:    tc.postings,
+			})
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	// nothing was written by the rejected journals
+	account, err := testQueries.GetAccount(context.Background(), usd.ID)
+	require.NoError(t, err)
+	require.Equal(t, usd.Balance, account.Balance)
+}
+
+func TestJournalBalancedConstraint(t *testing.T) {
+	account := createAccountIn(t, createRandomUser(t).Username, "USD", 0)
+
+	journal, err := testQueries.CreateJournalTransaction(context.Background(), CreateJournalTransactionParams{
+		Kind:        "adjustment",
+		Description: "unbalanced",
+	})
+	require.NoError(t, err)
+
+	// outside of an explicit transaction the deferred check runs as soon as
+	// the statement commits
+	_, err = testQueries.CreateEntry(context.Background(), CreateEntryParams{
+		AccountID: account.ID,
+		Amount:    10,
+		JournalID: journal.ID,
+	})
+	require.True(t, isJournalBalanceViolation(err), err)
+
+	entries, err := testQueries.ListJournalEntries(context.Background(), journal.ID)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestTransferTxJournal(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createAccountIn(t, createRandomUser(t).Username, "EUR", 100)
+	account2 := createAccountIn(t, createRandomUser(t).Username, "EUR", 0)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountId: account1.ID,
+		ToAccountId:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, result.FromEntry.JournalID, result.ToEntry.JournalID)
+
+	journal, err := testQueries.GetJournalTransaction(context.Background(), result.FromEntry.JournalID)
+	require.NoError(t, err)
+	require.Equal(t, JournalKindTransfer, journal.Kind)
+	require.Equal(t, result.Transfer.ID, journal.TransferID.Int64)
+}
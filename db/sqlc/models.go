@@ -5,7 +5,11 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Account struct {
@@ -14,6 +18,26 @@ type Account struct {
 	Balance   int64
 	Currency  string
 	CreatedAt time.Time
+	// how far below zero the balance may go
+	OverdraftLimit int64
+}
+
+type AccountEntrySum struct {
+	AccountID int64
+	// sum of the entries of the account up to the entry_checkpoint of the latest reconciliation run
+	EntrySum  int64
+	UpdatedAt time.Time
+}
+
+type Currency struct {
+	// ISO 4217 alphabetic code
+	Code string
+	// ISO 4217 numeric code
+	NumericCode int32
+	// number of minor-unit digits, 2 when amounts are in cents
+	Exponent  int16
+	Enabled   bool
+	CreatedAt time.Time
 }
 
 type Entry struct {
@@ -22,6 +46,119 @@ type Entry struct {
 	// can be negative or positive
 	Amount    int64
 	CreatedAt time.Time
+	// set when the entry was written by a transfer
+	TransferID sql.NullInt64
+	// the entries of a journal sum to zero in each currency
+	JournalID int64
+}
+
+type ExchangeRate struct {
+	ID            int64
+	BaseCurrency  string
+	QuoteCurrency string
+	// units of quote_currency for one unit of base_currency
+	Rate      string
+	CreatedAt time.Time
+}
+
+type FxQuote struct {
+	ID           uuid.UUID
+	Username     string
+	FromCurrency string
+	ToCurrency   string
+	// mid-market rate, units of to_currency for one unit of from_currency
+	Rate string
+	// margin taken off the rate, in basis points
+	SpreadBps int32
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+	CreatedAt time.Time
+}
+
+type IdempotencyKey struct {
+	Username    string
+	Key         string
+	RequestHash string
+	Response    json.RawMessage
+	CreatedAt   time.Time
+}
+
+type JournalTransaction struct {
+	ID int64
+	// transfer, fx_transfer, deposit, withdrawal, or the kind of a manual journal such as fee
+	Kind        string
+	Description string
+	// set when the journal was written by a transfer
+	TransferID sql.NullInt64
+	CreatedAt  time.Time
+}
+
+type ReconciliationRun struct {
+	ID int64
+	// ok, or drift when any mismatch or orphan was found
+	Status string
+	// highest entry id folded into account_entry_sums
+	EntryCheckpoint int64
+	// highest transfer id checked for a journal
+	TransferCheckpoint int64
+	AccountsChecked    int32
+	MismatchedAccounts int32
+	OrphanEntries      int32
+	OrphanTransfers    int32
+	Details            json.RawMessage
+	StartedAt          time.Time
+	FinishedAt         time.Time
+}
+
+type ScheduledTransfer struct {
+	ID            int64
+	Owner         string
+	FromAccountID int64
+	ToAccountID   int64
+	Amount        int64
+	// once, daily, weekly or monthly
+	Frequency string
+	StartAt   time.Time
+	// no occurrence of a standing order runs after this time
+	EndAt sql.NullTime
+	// the standing order completes after this many successful runs
+	MaxRuns sql.NullInt32
+	// active, paused, completed or cancelled
+	Status string
+	// index of the pending occurrence, counting from start_at
+	Occurrence int32
+	// when the pending occurrence is next attempted
+	NextRunAt time.Time
+	RunsDone  int32
+	// consecutive insufficient-funds failures of the pending occurrence
+	FailureCount int32
+	// set while a scheduler is running the pending occurrence
+	ClaimedUntil sql.NullTime
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type ScheduledTransferRun struct {
+	ID                  int64
+	ScheduledTransferID int64
+	Occurrence          int32
+	// succeeded or failed
+	Status string
+	// set when the run succeeded
+	TransferID sql.NullInt64
+	Error      string
+	CreatedAt  time.Time
+}
+
+type Session struct {
+	ID           uuid.UUID
+	Username     string
+	RefreshToken string
+	UserAgent    string
+	ClientIp     string
+	IsBlocked    bool
+	ExpiredAt    time.Time
+	CreatedAt    time.Time
 }
 
 type Transfer struct {
@@ -31,6 +168,13 @@ type Transfer struct {
 	// must be positive
 	Amount    int64
 	CreatedAt time.Time
+	// amount credited in the currency of to_account_id, set for cross-currency transfers
+	ToAmount sql.NullInt64
+	// mid-market rate of the quote the transfer used
+	FxRate sql.NullString
+	// spread taken off fx_rate, in basis points
+	FxSpreadBps sql.NullInt32
+	FxQuoteID   uuid.NullUUID
 }
 
 type User struct {
@@ -40,4 +184,5 @@ type User struct {
 	HashedPassword    string
 	PasswordChangedAt time.Time
 	CreatedAt         time.Time
+	Role              string
 }
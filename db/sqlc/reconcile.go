@@ -0,0 +1,170 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Statuses of a reconciliation run
+const (
+	ReconciliationOK    = "ok"
+	ReconciliationDrift = "drift"
+)
+
+// maxReconciliationDetails caps how many problems of each kind are listed in
+// the details of a run; the counts are always complete
+const maxReconciliationDetails = 100
+
+// ErrReconciliationRunning is returned by ReconcileTx when another run holds
+// the reconciliation lock
+var ErrReconciliationRunning = errors.New("another reconciliation run is in progress")
+
+// ReconcileTxParams contains the input parameters of the reconciliation
+// transaction
+type ReconcileTxParams struct {
+	// SettleDelay is how old entries and transfers must be before they are
+	// folded into the checkpoint. Rows written by transactions that were
+	// still open when a run started would otherwise be skipped for good.
+	SettleDelay time.Duration
+}
+
+// BalanceMismatch is an account whose balance differs from its entries
+type BalanceMismatch struct {
+	AccountID int64 `json:"account_id"`
+	Balance   int64 `json:"balance"`
+	EntrySum  int64 `json:"entry_sum"`
+}
+
+// ReconciliationDetails lists what a reconciliation run found
+type ReconciliationDetails struct {
+	Mismatches        []BalanceMismatch `json:"mismatches"`
+	OrphanEntryIDs    []int64           `json:"orphan_entry_ids"`
+	OrphanTransferIDs []int64           `json:"orphan_transfer_ids"`
+}
+
+// ReconcileTx checks that the balance of every account equals the sum of its
+// entries, that every transfer was written with a journal and that no entry
+// points at a transfer its journal does not belong to, then records the
+// outcome as a reconciliation run.
+//
+// Entry sums are kept per account up to a checkpoint, so each run only adds
+// the entries written since the previous one. Orphans are looked for among
+// the rows between the previous checkpoint and the new one.
+func (store *SqlStore) ReconcileTx(ctx context.Context, arg ReconcileTxParams) (ReconciliationRun, error) {
+	var run ReconciliationRun
+
+	// balances and entries are read from a single snapshot, in which every
+	// committed journal has updated both. A run that commits after this
+	// snapshot was taken has updated the same entry sums, so writing them
+	// again fails with a serialization failure and the run is retried.
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead}
+	err := store.execTx(ctx, opts, func(q *Queries) error {
+		locked, err := q.TryLockReconciliation(ctx)
+		if err != nil {
+			return err
+		}
+		if !locked {
+			return ErrReconciliationRunning
+		}
+
+		startedAt := time.Now()
+		settledBefore := startedAt.Add(-arg.SettleDelay)
+
+		previous, err := q.GetLatestReconciliationRun(ctx)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		entryCheckpoint, err := q.GetEntryCheckpoint(ctx, GetEntryCheckpointParams{
+			AfterID:       previous.EntryCheckpoint,
+			SettledBefore: settledBefore,
+		})
+		if err != nil {
+			return err
+		}
+		transferCheckpoint, err := q.GetTransferCheckpoint(ctx, GetTransferCheckpointParams{
+			AfterID:       previous.TransferCheckpoint,
+			SettledBefore: settledBefore,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.AddAccountEntrySums(ctx, AddAccountEntrySumsParams{
+			AfterID: previous.EntryCheckpoint,
+			UpToID:  entryCheckpoint,
+		})
+		if err != nil {
+			return err
+		}
+
+		mismatches, err := q.ListBalanceMismatches(ctx, entryCheckpoint)
+		if err != nil {
+			return err
+		}
+		orphanEntries, err := q.ListOrphanEntries(ctx, ListOrphanEntriesParams{
+			AfterID: previous.EntryCheckpoint,
+			UpToID:  entryCheckpoint,
+		})
+		if err != nil {
+			return err
+		}
+		orphanTransfers, err := q.ListOrphanTransfers(ctx, ListOrphanTransfersParams{
+			AfterID: previous.TransferCheckpoint,
+			UpToID:  transferCheckpoint,
+		})
+		if err != nil {
+			return err
+		}
+
+		accounts, err := q.CountAccounts(ctx)
+		if err != nil {
+			return err
+		}
+
+		details := ReconciliationDetails{
+			Mismatches:        make([]BalanceMismatch, 0, min(len(mismatches), maxReconciliationDetails)),
+			OrphanEntryIDs:    truncate(orphanEntries, maxReconciliationDetails),
+			OrphanTransferIDs: truncate(orphanTransfers, maxReconciliationDetails),
+		}
+		for _, mismatch := range truncate(mismatches, maxReconciliationDetails) {
+			details.Mismatches = append(details.Mismatches, BalanceMismatch(mismatch))
+		}
+		detailsJSON, err := json.Marshal(details)
+		if err != nil {
+			return err
+		}
+
+		status := ReconciliationOK
+		if len(mismatches) > 0 || len(orphanEntries) > 0 || len(orphanTransfers) > 0 {
+			status = ReconciliationDrift
+		}
+
+		run, err = q.CreateReconciliationRun(ctx, CreateReconciliationRunParams{
+			Status:             status,
+			EntryCheckpoint:    entryCheckpoint,
+			TransferCheckpoint: transferCheckpoint,
+			AccountsChecked:    int32(accounts),
+			MismatchedAccounts: int32(len(mismatches)),
+			OrphanEntries:      int32(len(orphanEntries)),
+			OrphanTransfers:    int32(len(orphanTransfers)),
+			Details:            detailsJSON,
+			StartedAt:          startedAt,
+		})
+		return err
+	})
+
+	return run, err
+}
+
+// truncate returns at most the first n items, never nil so that empty lists
+// are stored as [] rather than null
+func truncate[T any](items []T, n int) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items[:min(len(items), n)]
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func findMismatch(t *testing.T, run ReconciliationRun, accountID int64) *ListBalanceMismatchesRow {
+	mismatches, err := testQueries.ListBalanceMismatches(context.Background(), run.EntryCheckpoint)
+	require.NoError(t, err)
+	for i := range mismatches {
+		if mismatches[i].AccountID == accountID {
+			return &mismatches[i]
+		}
+	}
+	return nil
+}
+
+func TestReconcileTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	// written through journals, so its balance matches its entries
+	clean := createAccountIn(t, createRandomUser(t).Username, "USD", 0)
+	_, err := store.DepositTx(context.Background(), DepositTxParams{AccountID: clean.ID, Amount: 50})
+	require.NoError(t, err)
+
+	// given a balance without any entry
+	drifted := createAccountIn(t, createRandomUser(t).Username, "USD", 100)
+
+	run, err := store.ReconcileTx(context.Background(), ReconcileTxParams{})
+	require.NoError(t, err)
+	require.Equal(t, ReconciliationDrift, run.Status)
+	require.Positive(t, run.MismatchedAccounts)
+	require.NotZero(t, run.AccountsChecked)
+	require.WithinDuration(t, run.StartedAt, run.FinishedAt, time.Minute)
+
+	var details ReconciliationDetails
+	require.NoError(t, json.Unmarshal(run.Details, &details))
+	require.LessOrEqual(t, len(details.Mismatches), maxReconciliationDetails)
+
+	require.Nil(t, findMismatch(t, run, clean.ID))
+	mismatch := findMismatch(t, run, drifted.ID)
+	require.NotNil(t, mismatch)
+	require.Equal(t, int64(100), mismatch.Balance)
+	require.Equal(t, int64(0), mismatch.EntrySum)
+
+	latest, err := testQueries.GetLatestReconciliationRun(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, latest.ID, run.ID)
+
+	// entries written after the checkpoint are folded in by the next run
+	_, err = store.DepositTx(context.Background(), DepositTxParams{AccountID: clean.ID, Amount: 25})
+	require.NoError(t, err)
+
+	next, err := store.ReconcileTx(context.Background(), ReconcileTxParams{})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, next.EntryCheckpoint, run.EntryCheckpoint)
+	require.Nil(t, findMismatch(t, next, clean.ID))
+}
+
+func TestReconcileTxConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createAccountIn(t, createRandomUser(t).Username, "USD", 0)
+	_, err := store.DepositTx(context.Background(), DepositTxParams{AccountID: account.ID, Amount: 10})
+	require.NoError(t, err)
+
+	n := 5
+	errs := make(chan error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := store.ReconcileTx(context.Background(), ReconcileTxParams{})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			require.ErrorIs(t, err, ErrReconciliationRunning)
+		}
+	}
+
+	// overlapping runs must not add the same entries twice
+	run, err := testQueries.GetLatestReconciliationRun(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, findMismatch(t, run, account.ID))
+}
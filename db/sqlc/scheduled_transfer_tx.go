@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gurukanth/simplebank/schedule"
+)
+
+// Statuses of a scheduled transfer
+const (
+	ScheduleActive    = "active"
+	SchedulePaused    = "paused"
+	ScheduleCompleted = "completed"
+	ScheduleCancelled = "cancelled"
+)
+
+// Statuses of a scheduled transfer run
+const (
+	ScheduledRunSucceeded = "succeeded"
+	ScheduledRunFailed    = "failed"
+)
+
+// ErrStaleScheduledRun is returned by RecordScheduledRunTx when the
+// occurrence was already recorded, by a scheduler that claimed it again
+// after the claim of the first one lapsed
+var ErrStaleScheduledRun = errors.New("occurrence of the scheduled transfer was already recorded")
+
+// RecordScheduledRunTxParams contains the input parameters of the record
+// scheduled run transaction
+type RecordScheduledRunTxParams struct {
+	ScheduledTransferID int64
+	// Occurrence is the occurrence that was run, as it was claimed
+	Occurrence int32
+	// TransferID is the transfer made by a successful run
+	TransferID int64
+	// Err is why the run failed, nil when it succeeded
+	Err error
+	// RetryDelay is how long a failed occurrence waits before it is
+	// attempted again
+	RetryDelay time.Duration
+	// MaxFailures is how many consecutive insufficient-funds failures of an
+	// occurrence pause the scheduled transfer
+	MaxFailures int32
+}
+
+// RecordScheduledRunTxResult is the result of the record scheduled run
+// transaction
+type RecordScheduledRunTxResult struct {
+	ScheduledTransfer ScheduledTransfer
+	Run               ScheduledTransferRun
+}
+
+// RecordScheduledRunTx records the outcome of running an occurrence of a
+// scheduled transfer and releases the claim on it.
+//
+// After a success the transfer moves on to its next occurrence, or is
+// completed once it has no occurrence left. Occurrences that fell due
+// while it was paused or failing are skipped rather than run in a burst.
+// After a failure the same occurrence is attempted again after RetryDelay.
+// Only insufficient funds count toward MaxFailures, which pause the
+// transfer once reached; other errors are retried without being counted,
+// as they say nothing about the standing order itself.
+func (store *SqlStore) RecordScheduledRunTx(ctx context.Context, arg RecordScheduledRunTxParams) (RecordScheduledRunTxResult, error) {
+	var result RecordScheduledRunTxResult
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		scheduled, err := q.GetScheduledTransferForUpdate(ctx, arg.ScheduledTransferID)
+		if err != nil {
+			return err
+		}
+		if scheduled.Occurrence != arg.Occurrence {
+			return ErrStaleScheduledRun
+		}
+
+		run := CreateScheduledTransferRunParams{
+			ScheduledTransferID: scheduled.ID,
+			Occurrence:          scheduled.Occurrence,
+			Status:              ScheduledRunSucceeded,
+		}
+		progress := UpdateScheduledTransferProgressParams{
+			ID:           scheduled.ID,
+			Status:       scheduled.Status,
+			Occurrence:   scheduled.Occurrence,
+			NextRunAt:    scheduled.NextRunAt,
+			RunsDone:     scheduled.RunsDone,
+			FailureCount: scheduled.FailureCount,
+		}
+
+		now := time.Now()
+		if arg.Err == nil {
+			run.TransferID = sql.NullInt64{Int64: arg.TransferID, Valid: true}
+			progress.RunsDone++
+			progress.FailureCount = 0
+			if err := advanceSchedule(scheduled, &progress, now); err != nil {
+				return err
+			}
+			// cancelled while the occurrence was running
+			if scheduled.Status == ScheduleCancelled {
+				progress.Status = ScheduleCancelled
+			}
+		} else {
+			run.Status = ScheduledRunFailed
+			run.Error = arg.Err.Error()
+			progress.NextRunAt = now.Add(arg.RetryDelay)
+			if errors.Is(arg.Err, ErrInsufficientFunds) {
+				progress.FailureCount++
+				if progress.FailureCount >= arg.MaxFailures && progress.Status == ScheduleActive {
+					progress.Status = SchedulePaused
+				}
+			}
+		}
+
+		result.Run, err = q.CreateScheduledTransferRun(ctx, run)
+		if err != nil {
+			return err
+		}
+
+		result.ScheduledTransfer, err = q.UpdateScheduledTransferProgress(ctx, progress)
+		return err
+	})
+
+	return result, err
+}
+
+// advanceSchedule moves progress on to the next occurrence of scheduled
+// that falls due after now, or completes it when there is none
+func advanceSchedule(scheduled ScheduledTransfer, progress *UpdateScheduledTransferProgressParams, now time.Time) error {
+	if scheduled.Frequency == schedule.Once ||
+		(scheduled.MaxRuns.Valid && progress.RunsDone >= scheduled.MaxRuns.Int32) {
+		progress.Status = ScheduleCompleted
+		return nil
+	}
+
+	n, at, err := schedule.NextAfter(scheduled.Frequency, scheduled.StartAt, int(scheduled.Occurrence), now)
+	if err != nil {
+		return err
+	}
+	if scheduled.EndAt.Valid && at.After(scheduled.EndAt.Time) {
+		progress.Status = ScheduleCompleted
+		return nil
+	}
+
+	progress.Occurrence = int32(n)
+	progress.NextRunAt = at
+	return nil
+}
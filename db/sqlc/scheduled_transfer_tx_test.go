@@ -0,0 +1,209 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gurukanth/simplebank/schedule"
+	"github.com/stretchr/testify/require"
+)
+
+func createDueScheduledTransfer(t *testing.T, frequency string, maxRuns int32) (ScheduledTransfer, Account) {
+	owner := createRandomUser(t).Username
+	from := createAccountIn(t, owner, "USD", 0)
+	to := createAccountIn(t, createRandomUser(t).Username, "USD", 0)
+
+	arg := CreateScheduledTransferParams{
+		Owner:         owner,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        10,
+		Frequency:     frequency,
+		StartAt:       time.Now().Add(-time.Minute).Truncate(time.Microsecond),
+	}
+	if maxRuns > 0 {
+		arg.MaxRuns = sql.NullInt32{Int32: maxRuns, Valid: true}
+	}
+	scheduled, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, ScheduleActive, scheduled.Status)
+	require.WithinDuration(t, arg.StartAt, scheduled.NextRunAt, time.Millisecond)
+	return scheduled, from
+}
+
+func claimScheduledTransfer(t *testing.T, id int64) (ScheduledTransfer, bool) {
+	claimed, err := testQueries.ClaimDueScheduledTransfers(context.Background(), ClaimDueScheduledTransfersParams{
+		LeaseSeconds: 60,
+		BatchSize:    1000,
+	})
+	require.NoError(t, err)
+	for _, scheduled := range claimed {
+		if scheduled.ID == id {
+			require.True(t, scheduled.ClaimedUntil.Valid)
+			return scheduled, true
+		}
+	}
+	return ScheduledTransfer{}, false
+}
+
+func TestClaimDueScheduledTransfers(t *testing.T) {
+	scheduled, _ := createDueScheduledTransfer(t, schedule.Once, 0)
+
+	_, ok := claimScheduledTransfer(t, scheduled.ID)
+	require.True(t, ok)
+
+	// claimed rows are left alone until the claim lapses
+	_, ok = claimScheduledTransfer(t, scheduled.ID)
+	require.False(t, ok)
+}
+
+func TestRecordScheduledRunTxSucceeded(t *testing.T) {
+	store := NewStore(testDB)
+
+	scheduled, from := createDueScheduledTransfer(t, schedule.Daily, 2)
+	_, err := store.DepositTx(context.Background(), DepositTxParams{AccountID: from.ID, Amount: 100})
+	require.NoError(t, err)
+
+	claimed, ok := claimScheduledTransfer(t, scheduled.ID)
+	require.True(t, ok)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountId: claimed.FromAccountID,
+		ToAccountId:   claimed.ToAccountID,
+		Amount:        claimed.Amount,
+	})
+	require.NoError(t, err)
+
+	result, err := store.RecordScheduledRunTx(context.Background(), RecordScheduledRunTxParams{
+		ScheduledTransferID: claimed.ID,
+		Occurrence:          claimed.Occurrence,
+		TransferID:          transfer.Transfer.ID,
+		RetryDelay:          time.Hour,
+		MaxFailures:         3,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScheduledRunSucceeded, result.Run.Status)
+	require.Equal(t, transfer.Transfer.ID, result.Run.TransferID.Int64)
+
+	next := result.ScheduledTransfer
+	require.Equal(t, ScheduleActive, next.Status)
+	require.Equal(t, int32(1), next.Occurrence)
+	require.Equal(t, int32(1), next.RunsDone)
+	require.False(t, next.ClaimedUntil.Valid)
+	require.WithinDuration(t, scheduled.StartAt.AddDate(0, 0, 1), next.NextRunAt, time.Millisecond)
+
+	// a scheduler whose claim lapsed records the same occurrence too late
+	_, err = store.RecordScheduledRunTx(context.Background(), RecordScheduledRunTxParams{
+		ScheduledTransferID: claimed.ID,
+		Occurrence:          claimed.Occurrence,
+		TransferID:          transfer.Transfer.ID,
+		RetryDelay:          time.Hour,
+		MaxFailures:         3,
+	})
+	require.ErrorIs(t, err, ErrStaleScheduledRun)
+
+	// max_runs completes the standing order
+	result, err = store.RecordScheduledRunTx(context.Background(), RecordScheduledRunTxParams{
+		ScheduledTransferID: claimed.ID,
+		Occurrence:          1,
+		TransferID:          transfer.Transfer.ID,
+		RetryDelay:          time.Hour,
+		MaxFailures:         3,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScheduleCompleted, result.ScheduledTransfer.Status)
+	require.Equal(t, int32(2), result.ScheduledTransfer.RunsDone)
+
+	runs, err := testQueries.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduled.ID,
+		Limit:               10,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+}
+
+func TestRecordScheduledRunTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	scheduled, _ := createDueScheduledTransfer(t, schedule.Monthly, 0)
+
+	for i := int32(1); i <= 2; i++ {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountId: scheduled.FromAccountID,
+			ToAccountId:   scheduled.ToAccountID,
+			Amount:        scheduled.Amount,
+		})
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+
+		result, err := store.RecordScheduledRunTx(context.Background(), RecordScheduledRunTxParams{
+			ScheduledTransferID: scheduled.ID,
+			Occurrence:          0,
+			Err:                 err,
+			RetryDelay:          time.Hour,
+			MaxFailures:         2,
+		})
+		require.NoError(t, err)
+		require.Equal(t, ScheduledRunFailed, result.Run.Status)
+		require.Equal(t, ErrInsufficientFunds.Error(), result.Run.Error)
+		require.False(t, result.Run.TransferID.Valid)
+
+		// the same occurrence is retried until it has failed MaxFailures times
+		scheduled = result.ScheduledTransfer
+		require.Equal(t, int32(0), scheduled.Occurrence)
+		require.Equal(t, i, scheduled.FailureCount)
+		require.WithinDuration(t, time.Now().Add(time.Hour), scheduled.NextRunAt, time.Minute)
+	}
+	require.Equal(t, SchedulePaused, scheduled.Status)
+
+	resumed, err := testQueries.UpdateScheduledTransferStatus(context.Background(), UpdateScheduledTransferStatusParams{
+		ID:     scheduled.ID,
+		Status: ScheduleActive,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScheduleActive, resumed.Status)
+	require.Zero(t, resumed.FailureCount)
+
+	cancelled, err := testQueries.UpdateScheduledTransferStatus(context.Background(), UpdateScheduledTransferStatusParams{
+		ID:     scheduled.ID,
+		Status: ScheduleCancelled,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScheduleCancelled, cancelled.Status)
+
+	// a cancelled transfer stays cancelled
+	_, err = testQueries.UpdateScheduledTransferStatus(context.Background(), UpdateScheduledTransferStatusParams{
+		ID:     scheduled.ID,
+		Status: ScheduleActive,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestRecordScheduledRunTxOtherError(t *testing.T) {
+	store := NewStore(testDB)
+
+	scheduled, _ := createDueScheduledTransfer(t, schedule.Monthly, 0)
+	runErr := errors.New("connection reset by peer")
+
+	for i := 0; i < 3; i++ {
+		result, err := store.RecordScheduledRunTx(context.Background(), RecordScheduledRunTxParams{
+			ScheduledTransferID: scheduled.ID,
+			Occurrence:          0,
+			Err:                 runErr,
+			RetryDelay:          time.Hour,
+			MaxFailures:         2,
+		})
+		require.NoError(t, err)
+		require.Equal(t, ScheduledRunFailed, result.Run.Status)
+		require.Equal(t, runErr.Error(), result.Run.Error)
+
+		// errors other than insufficient funds are retried without counting
+		scheduled = result.ScheduledTransfer
+		require.Equal(t, int32(0), scheduled.Occurrence)
+		require.Zero(t, scheduled.FailureCount)
+		require.Equal(t, ScheduleActive, scheduled.Status)
+		require.WithinDuration(t, time.Now().Add(time.Hour), scheduled.NextRunAt, time.Minute)
+	}
+}
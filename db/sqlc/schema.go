@@ -0,0 +1,29 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gurukanth/simplebank/db/migration"
+)
+
+// SchemaVersion is the migration version this build expects the database to
+// be at, taken from the newest migration embedded in the binary
+var SchemaVersion = migration.LatestVersion()
+
+// Ping checks that the database can be reached
+func (store *SqlStore) Ping(ctx context.Context) error {
+	return store.db.PingContext(ctx)
+}
+
+// MigrationVersion returns the version recorded by golang-migrate and whether
+// the last migration failed half way. A database that was never migrated
+// reports version 0.
+func (store *SqlStore) MigrationVersion(ctx context.Context) (version int64, dirty bool, err error) {
+	err = store.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
@@ -0,0 +1,31 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/gurukanth/simplebank/db/migration"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrationVersion(t *testing.T) {
+	store := NewStore(testDB).(*SqlStore)
+
+	require.NoError(t, store.Ping(ctx))
+
+	version, dirty, err := store.MigrationVersion(ctx)
+	require.NoError(t, err)
+	require.False(t, dirty)
+	require.Equal(t, SchemaVersion, version)
+}
+
+func TestMigrationUpKeepsPoolOpen(t *testing.T) {
+	// TestMain has migrated testDB already, so this run has nothing to apply
+	require.NoError(t, migration.Up(testDB))
+
+	var one int
+	require.NoError(t, testDB.QueryRowContext(ctx, "SELECT 1").Scan(&one))
+	require.Equal(t, 1, one)
+
+	_, err := testQueries.CountAccounts(ctx)
+	require.NoError(t, err)
+}
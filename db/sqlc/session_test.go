@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSession(t *testing.T) Session {
+	user := createRandomUser(t)
+
+	arg := CreateSessionParams{
+		ID:           uuid.New(),
+		Username:     user.Username,
+		RefreshToken: "refresh-token",
+		UserAgent:    "test-agent",
+		ClientIp:     "127.0.0.1",
+		IsBlocked:    false,
+		ExpiredAt:    time.Now().Add(time.Hour),
+	}
+
+	session, err := testQueries.CreateSession(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, session)
+
+	require.Equal(t, arg.ID, session.ID)
+	require.Equal(t, arg.Username, session.Username)
+	require.Equal(t, arg.RefreshToken, session.RefreshToken)
+	require.Equal(t, arg.UserAgent, session.UserAgent)
+	require.Equal(t, arg.ClientIp, session.ClientIp)
+	require.False(t, session.IsBlocked)
+	require.WithinDuration(t, arg.ExpiredAt, session.ExpiredAt, time.Second)
+	require.NotZero(t, session.CreatedAt)
+
+	return session
+}
+
+func TestCreateSession(t *testing.T) {
+	createRandomSession(t)
+}
+
+func TestGetSession(t *testing.T) {
+	session1 := createRandomSession(t)
+	session2, err := testQueries.GetSession(context.Background(), session1.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, session2)
+
+	require.Equal(t, session1.ID, session2.ID)
+	require.Equal(t, session1.Username, session2.Username)
+	require.Equal(t, session1.RefreshToken, session2.RefreshToken)
+	require.WithinDuration(t, session1.ExpiredAt, session2.ExpiredAt, time.Second)
+}
+
+func TestBlockSession(t *testing.T) {
+	session1 := createRandomSession(t)
+
+	session2, err := testQueries.BlockSession(context.Background(), BlockSessionParams{
+		ID:       session1.ID,
+		Username: session1.Username,
+	})
+	require.NoError(t, err)
+	require.True(t, session2.IsBlocked)
+
+	sessions, err := testQueries.ListActiveSessions(context.Background(), session1.Username)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+}
+
+func TestBlockSessionOtherUser(t *testing.T) {
+	session1 := createRandomSession(t)
+
+	_, err := testQueries.BlockSession(context.Background(), BlockSessionParams{
+		ID:       session1.ID,
+		Username: "other_user",
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestBlockUserSessions(t *testing.T) {
+	session1 := createRandomSession(t)
+
+	sessions, err := testQueries.ListActiveSessions(context.Background(), session1.Username)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+
+	ids, err := testQueries.BlockUserSessions(context.Background(), session1.Username)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{session1.ID}, ids)
+
+	sessions, err = testQueries.ListActiveSessions(context.Background(), session1.Username)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+}
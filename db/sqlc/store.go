@@ -3,31 +3,111 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ErrInsufficientFunds is returned by TransferTx and WithdrawTx when the money
+// taken out would leave the account below its overdraft limit
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// ErrIdempotencyKeyReused is returned by TransferTx when an idempotency key is
+// replayed with a request that differs from the one it was first used with
+var ErrIdempotencyKeyReused = errors.New("idempotency key already used for a different request")
+
+const (
+	uniqueViolation = "23505"
+	checkViolation  = "23514"
 )
 
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	DepositTx(ctx context.Context, arg DepositTxParams) (CashTxResult, error)
+	WithdrawTx(ctx context.Context, arg WithdrawTxParams) (CashTxResult, error)
+	CrossCurrencyTransferTx(ctx context.Context, arg CrossCurrencyTransferTxParams) (TransferTxResult, error)
+	PostJournalTx(ctx context.Context, arg PostJournalTxParams) (PostJournalTxResult, error)
+	ReconcileTx(ctx context.Context, arg ReconcileTxParams) (ReconciliationRun, error)
+	RecordScheduledRunTx(ctx context.Context, arg RecordScheduledRunTxParams) (RecordScheduledRunTxResult, error)
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (version int64, dirty bool, err error)
+	Close() error
 }
 
 type SqlStore struct {
 	db *sql.DB
 	*Queries
+	txMaxRetries   int
+	txRetryBackoff time.Duration
+}
+
+// StoreOption configures optional behaviour of a SqlStore
+type StoreOption func(*SqlStore)
+
+// WithTxMaxRetries sets how many times a transaction failing with a
+// serialization failure or deadlock is retried before the error is returned
+func WithTxMaxRetries(n int) StoreOption {
+	return func(store *SqlStore) {
+		store.txMaxRetries = n
+	}
+}
+
+// WithTxRetryBackoff sets the base delay between transaction retries
+func WithTxRetryBackoff(d time.Duration) StoreOption {
+	return func(store *SqlStore) {
+		store.txRetryBackoff = d
+	}
 }
 
 // NewStore creates new store
-func NewStore(db *sql.DB) Store {
-	return &SqlStore{
-		db:      db,
-		Queries: New(db),
+func NewStore(db *sql.DB, opts ...StoreOption) Store {
+	store := &SqlStore{
+		db:             db,
+		Queries:        New(db),
+		txMaxRetries:   defaultTxMaxRetries,
+		txRetryBackoff: defaultTxRetryBackoff,
+	}
+	for _, opt := range opts {
+		opt(store)
+	}
+	return store
+}
+
+// Close closes the underlying database connection pool
+func (store *SqlStore) Close() error {
+	return store.db.Close()
+}
+
+// execTx executes a given function within the database transaction.
+// Serialization failures and deadlocks roll the transaction back and run fn
+// again, so fn must not have side effects outside of q.
+func (store *SqlStore) execTx(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
+	for attempt := 0; ; attempt++ {
+		err := store.runTx(ctx, opts, fn)
+		if err == nil || !isRetryableTxError(err) {
+			return err
+		}
+
+		if attempt >= store.txMaxRetries {
+			txMetrics.Add("retries_exhausted", 1)
+			return err
+		}
+		recordTxRetry(err)
+
+		if err := sleepBackoff(ctx, store.txRetryBackoff, attempt); err != nil {
+			return err
+		}
 	}
 }
 
-// execTx executes a given function within the database transaction
-func (store *SqlStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, nil)
+// runTx runs fn once within a new transaction
+func (store *SqlStore) runTx(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, opts)
 	if err != nil {
 		log.Println("Error Occurred while calling Begin Transaction", err)
 		return err
@@ -50,6 +130,12 @@ type TransferTxParams struct {
 	FromAccountId int64 `json:"from_account_id"`
 	ToAccountId   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	// IdempotencyKey makes the transfer safe to retry when set. The result is
+	// stored under (Username, IdempotencyKey) and replayed for repeated calls
+	// with the same RequestHash.
+	IdempotencyKey string `json:"idempotency_key"`
+	Username       string `json:"username"`
+	RequestHash    string `json:"request_hash"`
 }
 
 // TransferTxResult is the result of the transfer transaction
@@ -59,13 +145,14 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	// Replayed is set when the result was loaded from a stored idempotency key
+	Replayed bool `json:"-"`
 }
 
 // TransferTx performs a meoney transfer from one account to another one
 func (store *SqlStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
-	var result TransferTxResult
-
-	err := store.execTx(ctx, func(q *Queries) error {
+	return store.idempotentTransferTx(ctx, arg, func(q *Queries) (TransferTxResult, error) {
+		var result TransferTxResult
 		var err error
 
 		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
@@ -74,60 +161,102 @@ func (store *SqlStore) TransferTx(ctx context.Context, arg TransferTxParams) (Tr
 			Amount:        arg.Amount,
 		})
 		if err != nil {
-			return err
+			return result, err
 		}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountId,
-			Amount:    -arg.Amount,
-		})
+		journal, err := postJournal(ctx, q, PostJournalTxParams{
+			Kind:        JournalKindTransfer,
+			Description: fmt.Sprintf("transfer from account %d to account %d", arg.FromAccountId, arg.ToAccountId),
+			Postings: []Posting{
+				{AccountID: arg.FromAccountId, Amount: -arg.Amount},
+				{AccountID: arg.ToAccountId, Amount: arg.Amount},
+			},
+		}, sql.NullInt64{Int64: result.Transfer.ID, Valid: true})
 		if err != nil {
-			return err
+			return result, err
 		}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountId,
-			Amount:    arg.Amount,
-		})
+		result.FromEntry, result.ToEntry = journal.Entries[0], journal.Entries[1]
+		result.FromAccount, result.ToAccount = journal.account(arg.FromAccountId), journal.account(arg.ToAccountId)
+		return result, nil
+	})
+}
+
+// idempotentTransferTx runs transfer in a transaction, and stores or replays
+// the result when arg has an idempotency key
+func (store *SqlStore) idempotentTransferTx(
+	ctx context.Context,
+	arg TransferTxParams,
+	transfer func(q *Queries) (TransferTxResult, error),
+) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	if arg.IdempotencyKey != "" {
+		replayed, err := store.replayTransfer(ctx, arg)
+		if err == nil {
+			return replayed, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return result, err
+		}
+	}
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		var err error
+
+		result, err = transfer(q)
 		if err != nil {
 			return err
 		}
 
-		if arg.FromAccountId < arg.ToAccountId {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountId, -arg.Amount, arg.ToAccountId, arg.Amount)
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountId, arg.Amount, arg.FromAccountId, -arg.Amount)
+		if arg.IdempotencyKey == "" {
+			return nil
 		}
 
+		response, err := json.Marshal(result)
 		if err != nil {
 			return err
 		}
 
-		return nil
+		_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+			Username:    arg.Username,
+			Key:         arg.IdempotencyKey,
+			RequestHash: arg.RequestHash,
+			Response:    response,
+		})
+		return err
 	})
 
+	// a concurrent request with the same key committed first, so this
+	// transaction was rolled back and the stored result is returned instead
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && arg.IdempotencyKey != "" {
+		return store.replayTransfer(ctx, arg)
+	}
+
 	return result, err
 }
 
-func addMoney(
-	ctx context.Context,
-	q *Queries,
-	accountID1 int64,
-	amount1 int64,
-	accountID2 int64,
-	amount2 int64,
-) (account1 Account, account2 Account, err error) {
-	account1, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     accountID1,
-		Amount: amount1,
+// replayTransfer loads the result stored for the idempotency key of arg.
+// It returns sql.ErrNoRows when the key has not been used yet.
+func (store *SqlStore) replayTransfer(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	stored, err := store.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+		Username: arg.Username,
+		Key:      arg.IdempotencyKey,
 	})
 	if err != nil {
-		return
+		return result, err
 	}
 
-	account2, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     accountID2,
-		Amount: amount2,
-	})
-	return
+	if stored.RequestHash != arg.RequestHash {
+		return result, ErrIdempotencyKeyReused
+	}
+
+	if err := json.Unmarshal(stored.Response, &result); err != nil {
+		return result, err
+	}
+	result.Replayed = true
+	return result, nil
 }
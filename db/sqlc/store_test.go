@@ -5,14 +5,23 @@ import (
 	"log"
 	"testing"
 
+	"github.com/gurukanth/simplebank/util"
 	"github.com/stretchr/testify/require"
 )
 
+// createRandomAccountPair creates two accounts of different users in the
+// same currency, as journals only balance within a currency
+func createRandomAccountPair(t *testing.T) (Account, Account) {
+	currency := randomCurrency(t)
+	account1 := createAccountIn(t, createRandomUser(t).Username, currency, util.RandomMoney())
+	account2 := createAccountIn(t, createRandomUser(t).Username, currency, util.RandomMoney())
+	return account1, account2
+}
+
 func Test_TransferTx_ForDeadLock(t *testing.T) {
 	store := NewStore(testDB)
 
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account1, account2 := createRandomAccountPair(t)
 
 	//run n transfers
 	n := 100
@@ -63,8 +72,7 @@ func Test_TransferTx_ForDeadLock(t *testing.T) {
 func TestTransferTx(t *testing.T) {
 	store := NewStore(testDB)
 
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account1, account2 := createRandomAccountPair(t)
 	log.Println(">> before:", account1.Balance, account2.Balance)
 
 	//run n transfers
@@ -120,6 +128,7 @@ func TestTransferTx(t *testing.T) {
 		require.NotEmpty(t, fromEntry)
 		require.Equal(t, -amount, fromEntry.Amount)
 		require.Equal(t, account1.ID, transfer.FromAccountID)
+		require.Equal(t, transfer.ID, fromEntry.TransferID.Int64)
 		require.NotZero(t, fromEntry.ID)
 		require.NotZero(t, fromEntry.CreatedAt)
 
@@ -130,6 +139,7 @@ func TestTransferTx(t *testing.T) {
 		require.NotEmpty(t, toEntry)
 		require.Equal(t, amount, toEntry.Amount)
 		require.Equal(t, account1.ID, transfer.FromAccountID)
+		require.Equal(t, transfer.ID, toEntry.TransferID.Int64)
 		require.NotZero(t, toEntry.ID)
 		require.NotZero(t, toEntry.CreatedAt)
 
@@ -170,3 +180,168 @@ func TestTransferTx(t *testing.T) {
 	require.Equal(t, account2.Balance+int64(n)*amount, updatedAccount2.Balance)
 
 }
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1, account2 := createRandomAccountPair(t)
+
+	//run more transfers than the from account can afford
+	amount := int64(100)
+	allowed := int(account1.Balance / amount)
+	n := allowed + 10
+
+	errs := make(chan error)
+
+	for range n {
+		ctx := context.Background()
+		go func() {
+			_, err := store.TransferTx(ctx, TransferTxParams{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+			})
+			errs <- err
+		}()
+	}
+
+	//check results
+	succeeded := 0
+	for range n {
+		err := <-errs
+		if err != nil {
+			require.ErrorIs(t, err, ErrInsufficientFunds)
+			continue
+		}
+		succeeded++
+	}
+	require.Equal(t, allowed, succeeded)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+
+	updatedAccount2, err := testQueries.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+
+	require.Equal(t, account1.Balance-int64(allowed)*amount, updatedAccount1.Balance)
+	require.GreaterOrEqual(t, updatedAccount1.Balance, int64(0))
+	require.Equal(t, account2.Balance+int64(allowed)*amount, updatedAccount2.Balance)
+}
+
+func TestTransferTxOverdraftLimit(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1, account2 := createRandomAccountPair(t)
+
+	overdraftLimit := int64(500)
+	account1, err := testQueries.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: overdraftLimit,
+	})
+	require.NoError(t, err)
+
+	//run transfers in both directions, account1 may go below zero but never
+	//below -overdraftLimit
+	n := 200
+	amount := int64(100)
+
+	errs := make(chan error)
+
+	for i := range n {
+
+		var fromAccount int64
+		var toAccount int64
+
+		if i%4 == 0 {
+			fromAccount = account2.ID
+			toAccount = account1.ID
+		} else {
+			fromAccount = account1.ID
+			toAccount = account2.ID
+		}
+		ctx := context.Background()
+		go func() {
+			_, err := store.TransferTx(ctx, TransferTxParams{
+				FromAccountId: fromAccount,
+				ToAccountId:   toAccount,
+				Amount:        amount,
+			})
+			errs <- err
+		}()
+	}
+
+	//check results
+	for range n {
+		err := <-errs
+		if err != nil {
+			require.ErrorIs(t, err, ErrInsufficientFunds)
+		}
+	}
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+
+	updatedAccount2, err := testQueries.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+
+	require.GreaterOrEqual(t, updatedAccount1.Balance, -overdraftLimit)
+	require.GreaterOrEqual(t, updatedAccount2.Balance, int64(0))
+	require.Equal(t, account1.Balance+account2.Balance, updatedAccount1.Balance+updatedAccount2.Balance)
+}
+
+func TestTransferTxIdempotencyKey(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1, account2 := createRandomAccountPair(t)
+
+	arg := TransferTxParams{
+		FromAccountId:  account1.ID,
+		ToAccountId:    account2.ID,
+		Amount:         10,
+		IdempotencyKey: util.RandomString(32),
+		Username:       account1.Owner,
+		RequestHash:    util.RandomString(64),
+	}
+
+	//run the same request concurrently, only one transfer may be made
+	n := 10
+
+	errs := make(chan error)
+	results := make(chan TransferTxResult)
+
+	for range n {
+		ctx := context.Background()
+		go func() {
+			result, err := store.TransferTx(ctx, arg)
+			errs <- err
+			results <- result
+		}()
+	}
+
+	var transferID int64
+	replayed := 0
+	for range n {
+		err := <-errs
+		require.NoError(t, err)
+
+		result := <-results
+		if transferID == 0 {
+			transferID = result.Transfer.ID
+		}
+		require.Equal(t, transferID, result.Transfer.ID)
+		if result.Replayed {
+			replayed++
+		}
+	}
+	require.Equal(t, n-1, replayed)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-arg.Amount, updatedAccount1.Balance)
+
+	//a different request under the same key is rejected
+	arg.Amount = 20
+	arg.RequestHash = util.RandomString(64)
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+}
@@ -7,8 +7,61 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
 )
 
+const createCrossCurrencyTransfer = `-- name: CreateCrossCurrencyTransfer :one
+INSERT INTO transfers (
+  from_account_id,
+  to_account_id,
+  amount,
+  to_amount,
+  fx_rate,
+  fx_spread_bps,
+  fx_quote_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, fx_rate, fx_spread_bps, fx_quote_id
+`
+
+type CreateCrossCurrencyTransferParams struct {
+	FromAccountID int64
+	ToAccountID   int64
+	Amount        int64
+	ToAmount      sql.NullInt64
+	FxRate        sql.NullString
+	FxSpreadBps   sql.NullInt32
+	FxQuoteID     uuid.NullUUID
+}
+
+func (q *Queries) CreateCrossCurrencyTransfer(ctx context.Context, arg CreateCrossCurrencyTransferParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, createCrossCurrencyTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ToAmount,
+		arg.FxRate,
+		arg.FxSpreadBps,
+		arg.FxQuoteID,
+	)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.FxSpreadBps,
+		&i.FxQuoteID,
+	)
+	return i, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
   from_account_id,
@@ -16,7 +69,7 @@ INSERT INTO transfers (
   amount
 ) VALUES (
   $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+) RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, fx_rate, fx_spread_bps, fx_quote_id
 `
 
 type CreateTransferParams struct {
@@ -34,12 +87,16 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.FxSpreadBps,
+		&i.FxQuoteID,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, fx_rate, fx_spread_bps, fx_quote_id FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -52,34 +109,87 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.FxSpreadBps,
+		&i.FxQuoteID,
+	)
+	return i, err
+}
+
+const getTransferWithOwners = `-- name: GetTransferWithOwners :one
+SELECT
+  t.id,
+  t.from_account_id,
+  t.to_account_id,
+  t.amount,
+  t.created_at,
+  fa.owner AS from_owner,
+  ta.owner AS to_owner,
+  fa.currency,
+  ta.currency AS to_currency,
+  t.to_amount,
+  t.fx_rate,
+  t.fx_spread_bps
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE t.id = $1 LIMIT 1
+`
+
+type GetTransferWithOwnersRow struct {
+	ID            int64
+	FromAccountID int64
+	ToAccountID   int64
+	Amount        int64
+	CreatedAt     time.Time
+	FromOwner     string
+	ToOwner       string
+	Currency      string
+	ToCurrency    string
+	ToAmount      sql.NullInt64
+	FxRate        sql.NullString
+	FxSpreadBps   sql.NullInt32
+}
+
+func (q *Queries) GetTransferWithOwners(ctx context.Context, id int64) (GetTransferWithOwnersRow, error) {
+	row := q.db.QueryRowContext(ctx, getTransferWithOwners, id)
+	var i GetTransferWithOwnersRow
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.FromOwner,
+		&i.ToOwner,
+		&i.Currency,
+		&i.ToCurrency,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.FxSpreadBps,
 	)
 	return i, err
 }
 
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
-WHERE 
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, fx_rate, fx_spread_bps, fx_quote_id FROM transfers
+WHERE
     from_account_id = $1 OR
-    to_account_id = $2
+    to_account_id = $1
 ORDER BY id
-LIMIT $3
-OFFSET $4
+LIMIT $2
+OFFSET $3
 `
 
 type ListTransfersParams struct {
-	FromAccountID int64
-	ToAccountID   int64
-	Limit         int32
-	Offset        int32
+	AccountID   int64
+	LimitCount  int32
+	OffsetCount int32
 }
 
 func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
-	rows, err := q.db.QueryContext(ctx, listTransfers,
-		arg.FromAccountID,
-		arg.ToAccountID,
-		arg.Limit,
-		arg.Offset,
-	)
+	rows, err := q.db.QueryContext(ctx, listTransfers, arg.AccountID, arg.LimitCount, arg.OffsetCount)
 	if err != nil {
 		return nil, err
 	}
@@ -93,6 +203,332 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ToAmount,
+			&i.FxRate,
+			&i.FxSpreadBps,
+			&i.FxQuoteID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserTransfers = `-- name: ListUserTransfers :many
+SELECT
+  t.id,
+  t.from_account_id,
+  t.to_account_id,
+  t.amount,
+  t.created_at,
+  fa.owner AS from_owner,
+  ta.owner AS to_owner,
+  fa.currency,
+  ta.currency AS to_currency,
+  t.to_amount,
+  t.fx_rate,
+  t.fx_spread_bps
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE (fa.owner = $1 OR ta.owner = $1)
+  AND (
+    $2::varchar IS NULL
+    OR (fa.owner = $1 AND ta.owner = $2)
+    OR (ta.owner = $1 AND fa.owner = $2)
+  )
+  AND ($3::bigint IS NULL OR t.amount >= $3)
+  AND ($4::bigint IS NULL OR t.amount <= $4)
+  AND ($5::varchar IS NULL OR fa.currency = $5 OR ta.currency = $5)
+  AND ($6::timestamptz IS NULL OR t.created_at >= $6)
+  AND ($7::timestamptz IS NULL OR t.created_at < $7)
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT $8
+OFFSET $9
+`
+
+type ListUserTransfersParams struct {
+	Owner        string
+	Counterparty sql.NullString
+	MinAmount    sql.NullInt64
+	MaxAmount    sql.NullInt64
+	Currency     sql.NullString
+	FromTime     sql.NullTime
+	ToTime       sql.NullTime
+	LimitCount   int32
+	OffsetCount  int32
+}
+
+type ListUserTransfersRow struct {
+	ID            int64
+	FromAccountID int64
+	ToAccountID   int64
+	Amount        int64
+	CreatedAt     time.Time
+	FromOwner     string
+	ToOwner       string
+	Currency      string
+	ToCurrency    string
+	ToAmount      sql.NullInt64
+	FxRate        sql.NullString
+	FxSpreadBps   sql.NullInt32
+}
+
+func (q *Queries) ListUserTransfers(ctx context.Context, arg ListUserTransfersParams) ([]ListUserTransfersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserTransfers,
+		arg.Owner,
+		arg.Counterparty,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Currency,
+		arg.FromTime,
+		arg.ToTime,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserTransfersRow
+	for rows.Next() {
+		var i ListUserTransfersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.FromOwner,
+			&i.ToOwner,
+			&i.Currency,
+			&i.ToCurrency,
+			&i.ToAmount,
+			&i.FxRate,
+			&i.FxSpreadBps,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserTransfersAfter = `-- name: ListUserTransfersAfter :many
+SELECT
+  t.id,
+  t.from_account_id,
+  t.to_account_id,
+  t.amount,
+  t.created_at,
+  fa.owner AS from_owner,
+  ta.owner AS to_owner,
+  fa.currency,
+  ta.currency AS to_currency,
+  t.to_amount,
+  t.fx_rate,
+  t.fx_spread_bps
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE (fa.owner = $1 OR ta.owner = $1)
+  AND (
+    $2::varchar IS NULL
+    OR (fa.owner = $1 AND ta.owner = $2)
+    OR (ta.owner = $1 AND fa.owner = $2)
+  )
+  AND ($3::bigint IS NULL OR t.amount >= $3)
+  AND ($4::bigint IS NULL OR t.amount <= $4)
+  AND ($5::varchar IS NULL OR fa.currency = $5 OR ta.currency = $5)
+  AND ($6::timestamptz IS NULL OR t.created_at >= $6)
+  AND ($7::timestamptz IS NULL OR t.created_at < $7)
+  AND (t.created_at, t.id) < ($8::timestamptz, $9::bigint)
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT $10
+`
+
+type ListUserTransfersAfterParams struct {
+	Owner          string
+	Counterparty   sql.NullString
+	MinAmount      sql.NullInt64
+	MaxAmount      sql.NullInt64
+	Currency       sql.NullString
+	FromTime       sql.NullTime
+	ToTime         sql.NullTime
+	AfterCreatedAt time.Time
+	AfterID        int64
+	LimitCount     int32
+}
+
+type ListUserTransfersAfterRow struct {
+	ID            int64
+	FromAccountID int64
+	ToAccountID   int64
+	Amount        int64
+	CreatedAt     time.Time
+	FromOwner     string
+	ToOwner       string
+	Currency      string
+	ToCurrency    string
+	ToAmount      sql.NullInt64
+	FxRate        sql.NullString
+	FxSpreadBps   sql.NullInt32
+}
+
+func (q *Queries) ListUserTransfersAfter(ctx context.Context, arg ListUserTransfersAfterParams) ([]ListUserTransfersAfterRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserTransfersAfter,
+		arg.Owner,
+		arg.Counterparty,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Currency,
+		arg.FromTime,
+		arg.ToTime,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserTransfersAfterRow
+	for rows.Next() {
+		var i ListUserTransfersAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.FromOwner,
+			&i.ToOwner,
+			&i.Currency,
+			&i.ToCurrency,
+			&i.ToAmount,
+			&i.FxRate,
+			&i.FxSpreadBps,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserTransfersBefore = `-- name: ListUserTransfersBefore :many
+SELECT
+  t.id,
+  t.from_account_id,
+  t.to_account_id,
+  t.amount,
+  t.created_at,
+  fa.owner AS from_owner,
+  ta.owner AS to_owner,
+  fa.currency,
+  ta.currency AS to_currency,
+  t.to_amount,
+  t.fx_rate,
+  t.fx_spread_bps
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE (fa.owner = $1 OR ta.owner = $1)
+  AND (
+    $2::varchar IS NULL
+    OR (fa.owner = $1 AND ta.owner = $2)
+    OR (ta.owner = $1 AND fa.owner = $2)
+  )
+  AND ($3::bigint IS NULL OR t.amount >= $3)
+  AND ($4::bigint IS NULL OR t.amount <= $4)
+  AND ($5::varchar IS NULL OR fa.currency = $5 OR ta.currency = $5)
+  AND ($6::timestamptz IS NULL OR t.created_at >= $6)
+  AND ($7::timestamptz IS NULL OR t.created_at < $7)
+  AND (t.created_at, t.id) > ($8::timestamptz, $9::bigint)
+ORDER BY t.created_at, t.id
+LIMIT $10
+`
+
+type ListUserTransfersBeforeParams struct {
+	Owner           string
+	Counterparty    sql.NullString
+	MinAmount       sql.NullInt64
+	MaxAmount       sql.NullInt64
+	Currency        sql.NullString
+	FromTime        sql.NullTime
+	ToTime          sql.NullTime
+	BeforeCreatedAt time.Time
+	BeforeID        int64
+	LimitCount      int32
+}
+
+type ListUserTransfersBeforeRow struct {
+	ID            int64
+	FromAccountID int64
+	ToAccountID   int64
+	Amount        int64
+	CreatedAt     time.Time
+	FromOwner     string
+	ToOwner       string
+	Currency      string
+	ToCurrency    string
+	ToAmount      sql.NullInt64
+	FxRate        sql.NullString
+	FxSpreadBps   sql.NullInt32
+}
+
+func (q *Queries) ListUserTransfersBefore(ctx context.Context, arg ListUserTransfersBeforeParams) ([]ListUserTransfersBeforeRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserTransfersBefore,
+		arg.Owner,
+		arg.Counterparty,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Currency,
+		arg.FromTime,
+		arg.ToTime,
+		arg.BeforeCreatedAt,
+		arg.BeforeID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserTransfersBeforeRow
+	for rows.Next() {
+		var i ListUserTransfersBeforeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.FromOwner,
+			&i.ToOwner,
+			&i.Currency,
+			&i.ToCurrency,
+			&i.ToAmount,
+			&i.FxRate,
+			&i.FxSpreadBps,
 		); err != nil {
 			return nil, err
 		}
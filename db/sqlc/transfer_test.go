@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomTransfer(t *testing.T, from Account, to Account, amount int64) Transfer {
+	transfer, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, transfer.ID)
+	return transfer
+}
+
+func TestListTransfers(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account3 := createRandomAccount(t)
+
+	createRandomTransfer(t, account1, account2, 10)
+	createRandomTransfer(t, account2, account1, 20)
+	createRandomTransfer(t, account2, account3, 30)
+
+	//transfers in both directions touch account1
+	transfers, err := testQueries.ListTransfers(context.Background(), ListTransfersParams{
+		AccountID:   account1.ID,
+		LimitCount:  10,
+		OffsetCount: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 2)
+	for _, transfer := range transfers {
+		require.True(t, transfer.FromAccountID == account1.ID || transfer.ToAccountID == account1.ID)
+	}
+}
+
+func TestGetTransferWithOwners(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	transfer := createRandomTransfer(t, account1, account2, 10)
+
+	row, err := testQueries.GetTransferWithOwners(context.Background(), transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, transfer.ID, row.ID)
+	require.Equal(t, account1.Owner, row.FromOwner)
+	require.Equal(t, account2.Owner, row.ToOwner)
+	require.Equal(t, account1.Currency, row.Currency)
+}
+
+func TestListUserTransfers(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account3 := createRandomAccount(t)
+
+	createRandomTransfer(t, account1, account2, 10)
+	createRandomTransfer(t, account2, account1, 20)
+	createRandomTransfer(t, account1, account3, 30)
+	createRandomTransfer(t, account2, account3, 40)
+
+	arg := ListUserTransfersParams{
+		Owner:       account1.Owner,
+		LimitCount:  10,
+		OffsetCount: 0,
+	}
+
+	transfers, err := testQueries.ListUserTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 3)
+
+	//newest first
+	require.Equal(t, int64(30), transfers[0].Amount)
+	require.Equal(t, account1.Owner, transfers[0].FromOwner)
+	require.Equal(t, account3.Owner, transfers[0].ToOwner)
+
+	arg.Counterparty = sql.NullString{String: account2.Owner, Valid: true}
+	transfers, err = testQueries.ListUserTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 2)
+
+	arg.MinAmount = sql.NullInt64{Int64: 15, Valid: true}
+	transfers, err = testQueries.ListUserTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.Equal(t, int64(20), transfers[0].Amount)
+
+	arg.MaxAmount = sql.NullInt64{Int64: 15, Valid: true}
+	transfers, err = testQueries.ListUserTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+}
+
+func TestListUserTransfersKeyset(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	var transfers []Transfer
+	for i := range 3 {
+		transfers = append(transfers, createRandomTransfer(t, account1, account2, int64(i+1)))
+	}
+
+	//newest first, so the rows after the newest transfer are the older ones
+	after, err := testQueries.ListUserTransfersAfter(context.Background(), ListUserTransfersAfterParams{
+		Owner:          account1.Owner,
+		AfterCreatedAt: transfers[2].CreatedAt,
+		AfterID:        transfers[2].ID,
+		LimitCount:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, after, 2)
+	require.Equal(t, transfers[1].ID, after[0].ID)
+	require.Equal(t, transfers[0].ID, after[1].ID)
+
+	before, err := testQueries.ListUserTransfersBefore(context.Background(), ListUserTransfersBeforeParams{
+		Owner:           account1.Owner,
+		BeforeCreatedAt: transfers[0].CreatedAt,
+		BeforeID:        transfers[0].ID,
+		LimitCount:      5,
+	})
+	require.NoError(t, err)
+	require.Len(t, before, 2)
+	require.Equal(t, transfers[1].ID, before[0].ID)
+	require.Equal(t, transfers[2].ID, before[1].ID)
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"math/rand"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	defaultTxMaxRetries   = 3
+	defaultTxRetryBackoff = 20 * time.Millisecond
+	maxTxRetryBackoff     = time.Second
+
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// txMetrics counts transaction retries, published to admins under /debug/vars
+var txMetrics = expvar.NewMap("db_tx")
+
+// isRetryableTxError reports whether err is a serialization failure or a
+// deadlock, after which the whole transaction can safely be run again
+func isRetryableTxError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == serializationFailure || pqErr.Code == deadlockDetected
+}
+
+func recordTxRetry(err error) {
+	txMetrics.Add("retries", 1)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == deadlockDetected {
+		txMetrics.Add("deadlocks", 1)
+		return
+	}
+	txMetrics.Add("serialization_failures", 1)
+}
+
+// sleepBackoff waits for a random duration up to base * 2^attempt, capped at
+// maxTxRetryBackoff, or until ctx is done
+func sleepBackoff(ctx context.Context, base time.Duration, attempt int) error {
+	if base <= 0 {
+		return ctx.Err()
+	}
+
+	backoff := base << attempt
+	if backoff <= 0 || backoff > maxTxRetryBackoff {
+		backoff = maxTxRetryBackoff
+	}
+
+	timer := time.NewTimer(time.Duration(rand.Int63n(int64(backoff)) + 1))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func txMetric(name string) int64 {
+	v, ok := txMetrics.Get(name).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+	return v.Value()
+}
+
+func TestExecTxRetriesSerializationFailure(t *testing.T) {
+	store := NewStore(testDB, WithTxMaxRetries(3), WithTxRetryBackoff(time.Millisecond)).(*SqlStore)
+
+	retries := txMetric("retries")
+	calls := 0
+	err := store.execTx(context.Background(), nil, func(q *Queries) error {
+		calls++
+		if calls < 3 {
+			return &pq.Error{Code: serializationFailure}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+	require.Equal(t, retries+2, txMetric("retries"))
+}
+
+func TestExecTxRetriesExhausted(t *testing.T) {
+	store := NewStore(testDB, WithTxMaxRetries(2), WithTxRetryBackoff(time.Millisecond)).(*SqlStore)
+
+	exhausted := txMetric("retries_exhausted")
+	calls := 0
+	err := store.execTx(context.Background(), nil, func(q *Queries) error {
+		calls++
+		return &pq.Error{Code: deadlockDetected}
+	})
+	require.True(t, isRetryableTxError(err))
+	require.Equal(t, 3, calls)
+	require.Equal(t, exhausted+1, txMetric("retries_exhausted"))
+}
+
+func TestExecTxDoesNotRetryOtherErrors(t *testing.T) {
+	store := NewStore(testDB).(*SqlStore)
+
+	calls := 0
+	err := store.execTx(context.Background(), nil, func(q *Queries) error {
+		calls++
+		return sql.ErrNoRows
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	require.Equal(t, 1, calls)
+}
+
+func TestExecTxSerializableConflicts(t *testing.T) {
+	store := NewStore(testDB, WithTxMaxRetries(50), WithTxRetryBackoff(time.Millisecond)).(*SqlStore)
+
+	account := createRandomAccount(t)
+	retries := txMetric("retries")
+
+	//concurrent read-modify-write under SERIALIZABLE conflicts, every
+	//increment must still be applied exactly once
+	n := 10
+	errs := make(chan error)
+
+	for range n {
+		go func() {
+			errs <- store.execTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelSerializable}, func(q *Queries) error {
+				acc, err := q.GetAccount(context.Background(), account.ID)
+				if err != nil {
+					return err
+				}
+
+				_, err = q.UpdateAccount(context.Background(), UpdateAccountParams{
+					ID:      acc.ID,
+					Balance: acc.Balance + 1,
+				})
+				return err
+			})
+		}()
+	}
+
+	for range n {
+		err := <-errs
+		require.NoError(t, err)
+	}
+
+	updatedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+int64(n), updatedAccount.Balance)
+	require.Greater(t, txMetric("retries"), retries)
+}
+
+func TestExecTxReadOnly(t *testing.T) {
+	store := NewStore(testDB).(*SqlStore)
+
+	account := createRandomAccount(t)
+
+	err := store.execTx(context.Background(), &sql.TxOptions{ReadOnly: true}, func(q *Queries) error {
+		_, err := q.UpdateAccount(context.Background(), UpdateAccountParams{
+			ID:      account.ID,
+			Balance: account.Balance + 1,
+		})
+		return err
+	})
+	require.Error(t, err)
+	require.False(t, isRetryableTxError(err))
+}
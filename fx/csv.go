@@ -0,0 +1,56 @@
+package fx
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Rate is a single exchange rate read from a rates file
+type Rate struct {
+	Base  string
+	Quote string
+	Rate  string
+}
+
+// ReadRates reads exchange rates from CSV with the columns
+// base_currency,quote_currency,rate. A header row naming those columns is
+// skipped, and every rate is checked with ParseRate.
+func ReadRates(r io.Reader) ([]Rate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	var rates []Rate
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		if len(rates) == 0 && strings.EqualFold(record[0], "base_currency") {
+			continue
+		}
+
+		rate := Rate{
+			Base:  strings.ToUpper(strings.TrimSpace(record[0])),
+			Quote: strings.ToUpper(strings.TrimSpace(record[1])),
+			Rate:  strings.TrimSpace(record[2]),
+		}
+		if rate.Base == rate.Quote {
+			return nil, fmt.Errorf("line %d: base and quote currency are both %s", line, rate.Base)
+		}
+		if _, err := ParseRate(rate.Rate); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
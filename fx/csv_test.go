@@ -0,0 +1,40 @@
+package fx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRates(t *testing.T) {
+	rates, err := ReadRates(strings.NewReader(`base_currency,quote_currency,rate
+# mid-market rates at close
+EUR,USD,1.0842
+usd, inr, 83.12
+`))
+	require.NoError(t, err)
+	require.Equal(t, []Rate{
+		{Base: "EUR", Quote: "USD", Rate: "1.0842"},
+		{Base: "USD", Quote: "INR", Rate: "83.12"},
+	}, rates)
+}
+
+func TestReadRatesInvalid(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{name: "BadRate", input: "EUR,USD,abc\n", err: "line 1"},
+		{name: "SameCurrency", input: "EUR,USD,1.1\nUSD,USD,1\n", err: "line 2"},
+		{name: "MissingColumn", input: "EUR,USD\n", err: "wrong number of fields"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ReadRates(strings.NewReader(tc.input))
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package fx
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+)
+
+// RateScale is the number of decimal places rates are stored with
+const RateScale = 10
+
+// maxSpreadBps is a spread of 100%, past which every conversion would be
+// worth nothing
+const maxSpreadBps = 10000
+
+var rateRegexp = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+var errAmountOutOfRange = errors.New("converted amount is out of range")
+
+// ParseRate parses a positive decimal exchange rate such as "1.0842"
+func ParseRate(s string) (*big.Rat, error) {
+	if !rateRegexp.MatchString(s) {
+		return nil, fmt.Errorf("rate %q is not a decimal number", s)
+	}
+
+	rate, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("rate %q is not a decimal number", s)
+	}
+	if rate.Sign() <= 0 {
+		return nil, fmt.Errorf("rate %q must be greater than 0", s)
+	}
+	return rate, nil
+}
+
+// FormatRate renders rate with RateScale decimal places, the precision the
+// database stores it with
+func FormatRate(rate *big.Rat) string {
+	return rate.FloatString(RateScale)
+}
+
+// Invert returns the rate of the opposite direction, 1/rate
+func Invert(rate *big.Rat) *big.Rat {
+	return new(big.Rat).Inv(rate)
+}
+
+// ApplySpread returns the rate a customer gets once spreadBps basis points
+// are taken off the mid-market rate
+func ApplySpread(rate *big.Rat, spreadBps int32) (*big.Rat, error) {
+	if spreadBps < 0 || spreadBps >= maxSpreadBps {
+		return nil, fmt.Errorf("spread of %d bps is out of range", spreadBps)
+	}
+
+	factor := big.NewRat(int64(maxSpreadBps-spreadBps), maxSpreadBps)
+	return new(big.Rat).Mul(rate, factor), nil
+}
+
+// Convert converts amount minor units of a currency with exponent fromExp
+// into minor units of a currency with exponent toExp at the mid-market rate
+// less spreadBps. The result is rounded down so the house never pays out
+// more than the rate allows.
+func Convert(amount int64, rate *big.Rat, spreadBps int32, fromExp, toExp int16) (int64, error) {
+	clientRate, err := ApplySpread(rate, spreadBps)
+	if err != nil {
+		return 0, err
+	}
+
+	converted := new(big.Rat).Mul(big.NewRat(amount, 1), clientRate)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(toExp-fromExp))), nil)
+	if toExp > fromExp {
+		converted.Mul(converted, new(big.Rat).SetInt(scale))
+	} else {
+		converted.Quo(converted, new(big.Rat).SetInt(scale))
+	}
+
+	// amounts are positive, so truncating the quotient rounds down
+	result := new(big.Int).Quo(converted.Num(), converted.Denom())
+	if !result.IsInt64() {
+		return 0, errAmountOutOfRange
+	}
+	return result.Int64(), nil
+}
+
+func abs(n int16) int16 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
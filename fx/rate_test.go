@@ -0,0 +1,66 @@
+package fx
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRate(t *testing.T) {
+	rate, err := ParseRate("1.0842")
+	require.NoError(t, err)
+	require.Equal(t, "1.0842000000", FormatRate(rate))
+
+	for _, s := range []string{"", "0", "0.000", "-1.2", "1e3", "abc", "1."} {
+		_, err := ParseRate(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestInvert(t *testing.T) {
+	rate, err := ParseRate("4")
+	require.NoError(t, err)
+	require.Equal(t, "0.2500000000", FormatRate(Invert(rate)))
+}
+
+func TestConvert(t *testing.T) {
+	testCases := []struct {
+		name      string
+		amount    int64
+		rate      string
+		spreadBps int32
+		fromExp   int16
+		toExp     int16
+		want      int64
+	}{
+		{name: "SameExponent", amount: 10000, rate: "1.1", spreadBps: 0, fromExp: 2, toExp: 2, want: 11000},
+		{name: "Spread", amount: 10000, rate: "1.1", spreadBps: 50, fromExp: 2, toExp: 2, want: 10945},
+		{name: "RoundsDown", amount: 1, rate: "0.999", spreadBps: 0, fromExp: 2, toExp: 2, want: 0},
+		{name: "ToZeroExponent", amount: 10050, rate: "150.25", spreadBps: 0, fromExp: 2, toExp: 0, want: 15100},
+		{name: "FromZeroExponent", amount: 1000, rate: "0.0066", spreadBps: 0, fromExp: 0, toExp: 2, want: 660},
+		{name: "ToThreeDecimals", amount: 100, rate: "0.3075", spreadBps: 0, fromExp: 2, toExp: 3, want: 307},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rate, err := ParseRate(tc.rate)
+			require.NoError(t, err)
+
+			got, err := Convert(tc.amount, rate, tc.spreadBps, tc.fromExp, tc.toExp)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestConvertInvalid(t *testing.T) {
+	_, err := Convert(100, big.NewRat(1, 1), 10000, 2, 2)
+	require.Error(t, err)
+
+	_, err = Convert(100, big.NewRat(1, 1), -1, 2, 2)
+	require.Error(t, err)
+
+	_, err = Convert(1<<62, big.NewRat(10, 1), 0, 2, 2)
+	require.ErrorIs(t, err, errAmountOutOfRange)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/gurukanth/simplebank/db/migration"
+)
+
+const migrateUsage = "usage: simplebank migrate up | down N | version | force VERSION"
+
+// runMigrate applies the embedded migrations to conn as directed by args,
+// the command line after "migrate"
+func runMigrate(conn *sql.DB, args []string) (err error) {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	m, err := migration.New(conn)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	switch args[0] {
+	case "up":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+		err = m.Up()
+	case "down":
+		n, convErr := migrateArg(args)
+		if convErr != nil {
+			return convErr
+		}
+		if n <= 0 {
+			return fmt.Errorf("down needs a positive number of migrations, got %d", n)
+		}
+		err = m.Steps(-n)
+	case "force":
+		version, convErr := migrateArg(args)
+		if convErr != nil {
+			return convErr
+		}
+		err = m.Force(version)
+	case "version":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	if errors.Is(err, migrate.ErrNoChange) {
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		slog.Info("db has no migrations applied")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	slog.Info("db schema version", "version", version, "dirty", dirty, "latest", migration.LatestVersion())
+	return nil
+}
+
+// migrateArg parses the single integer argument of down and force
+func migrateArg(args []string) (int, error) {
+	if len(args) != 2 {
+		return 0, errors.New(migrateUsage)
+	}
+	n, err := strconv.Atoi(args[1])
+	if err != nil {
+		return 0, fmt.Errorf("%s: %q is not a number", args[0], args[1])
+	}
+	return n, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	db "github.com/gurukanth/simplebank/db/sqlc"
+	"github.com/gurukanth/simplebank/fx"
+)
+
+const ratesUsage = "usage: simplebank rates import FILE"
+
+// runRates loads exchange rates into the database as directed by args, the
+// command line after "rates". The rates of a file are imported all or
+// nothing.
+func runRates(conn *sql.DB, args []string) error {
+	if len(args) != 2 || args[0] != "import" {
+		return errors.New(ratesUsage)
+	}
+
+	file, err := os.Open(args[1])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	rates, err := fx.ReadRates(file)
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %w", args[1], err)
+	}
+
+	ctx := context.Background()
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := db.New(tx)
+	for _, rate := range rates {
+		_, err := q.CreateExchangeRate(ctx, db.CreateExchangeRateParams{
+			BaseCurrency:  rate.Base,
+			QuoteCurrency: rate.Quote,
+			Rate:          rate.Rate,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot import %s/%s: %w", rate.Base, rate.Quote, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	slog.Info("exchange rates imported", "file", args[1], "count", len(rates))
+	return nil
+}
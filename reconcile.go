@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	db "github.com/gurukanth/simplebank/db/sqlc"
+)
+
+// runReconcile reconciles the ledger once, for use from cron or by hand. It
+// fails when the run finds drift, so the exit status can raise an alert.
+func runReconcile(store db.Store, settleDelay time.Duration) error {
+	run, err := store.ReconcileTx(context.Background(), db.ReconcileTxParams{
+		SettleDelay: settleDelay,
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.Info("reconciliation finished",
+		"run_id", run.ID,
+		"status", run.Status,
+		"accounts_checked", run.AccountsChecked,
+		"entry_checkpoint", run.EntryCheckpoint,
+		"transfer_checkpoint", run.TransferCheckpoint,
+	)
+	if run.Status != db.ReconciliationOK {
+		return fmt.Errorf("ledger drift found: %d mismatched accounts, %d orphan entries, %d orphan transfers",
+			run.MismatchedAccounts, run.OrphanEntries, run.OrphanTransfers)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Package schedule works out when the occurrences of a recurring transfer
+// fall due.
+package schedule
+
+import (
+	"fmt"
+	"time"
+)
+
+// Frequencies a transfer can be scheduled with
+const (
+	Once    = "once"
+	Daily   = "daily"
+	Weekly  = "weekly"
+	Monthly = "monthly"
+)
+
+// Occurrence returns when occurrence n, counting from 0, of a schedule
+// starting at start falls due. Monthly occurrences keep the day of the month
+// of start, moved back to the last day of shorter months, so a schedule
+// starting on January 31 runs on February 28 and then on March 31.
+func Occurrence(frequency string, start time.Time, n int) (time.Time, error) {
+	switch frequency {
+	case Once:
+		if n != 0 {
+			return time.Time{}, fmt.Errorf("a %s schedule has a single occurrence", Once)
+		}
+		return start, nil
+	case Daily:
+		return start.AddDate(0, 0, n), nil
+	case Weekly:
+		return start.AddDate(0, 0, 7*n), nil
+	case Monthly:
+		year, month, day := start.Date()
+		month += time.Month(n)
+		// day 0 of the following month is the last day of this one
+		if last := time.Date(year, month+1, 0, 0, 0, 0, 0, start.Location()).Day(); day > last {
+			day = last
+		}
+		return time.Date(year, month, day,
+			start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location()), nil
+	default:
+		return time.Time{}, fmt.Errorf("unknown frequency %q", frequency)
+	}
+}
+
+// NextAfter returns the first occurrence later than n that falls due after
+// t, along with its index. Occurrences missed in between, for example while
+// the schedule was paused, are skipped.
+func NextAfter(frequency string, start time.Time, n int, t time.Time) (int, time.Time, error) {
+	for {
+		n++
+		at, err := Occurrence(frequency, start, n)
+		if err != nil {
+			return 0, time.Time{}, err
+		}
+		if at.After(t) {
+			return n, at, nil
+		}
+	}
+}
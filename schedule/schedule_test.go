@@ -0,0 +1,63 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOccurrence(t *testing.T) {
+	start := time.Date(2024, time.January, 31, 9, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name      string
+		frequency string
+		n         int
+		want      time.Time
+	}{
+		{"Once", Once, 0, start},
+		{"Daily", Daily, 3, time.Date(2024, time.February, 3, 9, 30, 0, 0, time.UTC)},
+		{"Weekly", Weekly, 2, time.Date(2024, time.February, 14, 9, 30, 0, 0, time.UTC)},
+		{"MonthlyLeapFebruary", Monthly, 1, time.Date(2024, time.February, 29, 9, 30, 0, 0, time.UTC)},
+		{"MonthlyBackToLongMonth", Monthly, 2, time.Date(2024, time.March, 31, 9, 30, 0, 0, time.UTC)},
+		{"MonthlyShortMonth", Monthly, 3, time.Date(2024, time.April, 30, 9, 30, 0, 0, time.UTC)},
+		{"MonthlyNextYear", Monthly, 13, time.Date(2025, time.February, 28, 9, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Occurrence(tc.frequency, start, tc.n)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestOccurrenceInvalid(t *testing.T) {
+	start := time.Now()
+
+	_, err := Occurrence(Once, start, 1)
+	require.Error(t, err)
+
+	_, err = Occurrence("yearly", start, 0)
+	require.Error(t, err)
+}
+
+func TestNextAfter(t *testing.T) {
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	n, at, err := NextAfter(Daily, start, 0, start)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, start.AddDate(0, 0, 1), at)
+
+	// occurrences 1 to 9 were missed
+	n, at, err = NextAfter(Daily, start, 0, start.AddDate(0, 0, 9).Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 10, n)
+	require.Equal(t, start.AddDate(0, 0, 10), at)
+
+	_, _, err = NextAfter(Once, start, 0, start)
+	require.Error(t, err)
+}
@@ -0,0 +1,217 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// keyFileExt is the extension of the key files loaded from the key directory.
+// Renaming or removing a file retires its key.
+const keyFileExt = ".pem"
+
+type signingKey struct {
+	id         string
+	method     jwt.SigningMethod
+	privateKey crypto.Signer
+	publicKey  crypto.PublicKey
+}
+
+// AsymmetricJWTMaker signs JWTs with Ed25519 (EdDSA) or P-256 (ES256) keys.
+// Every token carries the kid of the key that signed it, and tokens signed by
+// any loaded key are accepted so keys can be rotated without downtime.
+type AsymmetricJWTMaker struct {
+	keys       map[string]*signingKey
+	signingKey *signingKey
+}
+
+// NewAsymmetricJWTMaker loads every <kid>.pem private key in keyDir. Tokens
+// are signed with signingKeyID, or with the key whose kid sorts last when
+// signingKeyID is empty.
+func NewAsymmetricJWTMaker(keyDir string, signingKeyID string) (*AsymmetricJWTMaker, error) {
+	files, err := filepath.Glob(filepath.Join(keyDir, "*"+keyFileExt))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no %s keys found in %s", keyFileExt, keyDir)
+	}
+	sort.Strings(files)
+
+	maker := &AsymmetricJWTMaker{keys: make(map[string]*signingKey, len(files))}
+	for _, file := range files {
+		key, err := loadSigningKey(file)
+		if err != nil {
+			return nil, err
+		}
+		maker.keys[key.id] = key
+		maker.signingKey = key
+	}
+
+	if signingKeyID != "" {
+		key, ok := maker.keys[signingKeyID]
+		if !ok {
+			return nil, fmt.Errorf("signing key %q not found in %s", signingKeyID, keyDir)
+		}
+		maker.signingKey = key
+	}
+
+	return maker, nil
+}
+
+func loadSigningKey(file string) (*signingKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", file)
+	}
+
+	var parsed any
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		err = fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	key := &signingKey{id: strings.TrimSuffix(filepath.Base(file), keyFileExt)}
+	switch privateKey := parsed.(type) {
+	case ed25519.PrivateKey:
+		key.method = jwt.SigningMethodEdDSA
+		key.privateKey = privateKey
+		key.publicKey = privateKey.Public()
+	case *ecdsa.PrivateKey:
+		if privateKey.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%s: only P-256 ECDSA keys are supported", file)
+		}
+		key.method = jwt.SigningMethodES256
+		key.privateKey = privateKey
+		key.publicKey = privateKey.Public()
+	default:
+		return nil, fmt.Errorf("%s: unsupported key type %T", file, parsed)
+	}
+
+	return key, nil
+}
+
+func (maker *AsymmetricJWTMaker) CreateToken(tokenType string, username string, role string, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(tokenType, username, role, sessionID, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	jwtToken := jwt.NewWithClaims(maker.signingKey.method, newJWTClaims(payload))
+	jwtToken.Header["kid"] = maker.signingKey.id
+
+	token, err := jwtToken.SignedString(maker.signingKey.privateKey)
+	return token, payload, err
+}
+
+func (maker *AsymmetricJWTMaker) VerifyToken(token string) (*Payload, error) {
+	keyFunc := func(jwtToken *jwt.Token) (interface{}, error) {
+		kid, ok := jwtToken.Header["kid"].(string)
+		if !ok {
+			return nil, ErrorInvalidToken
+		}
+
+		key, ok := maker.keys[kid]
+		if !ok || key.method.Alg() != jwtToken.Method.Alg() {
+			return nil, ErrorInvalidToken
+		}
+		return key.publicKey, nil
+	}
+
+	claims := &jwtClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, keyFunc,
+		jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg(), jwt.SigningMethodES256.Alg()}),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrorExpiredToken
+		}
+		return nil, ErrorInvalidToken
+	}
+
+	return &claims.Payload, nil
+}
+
+// JSONWebKey is the public part of a signing key in RFC 7517 format
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	Y         string `json:"y,omitempty"`
+}
+
+// JSONWebKeySet is the document served on the JWKS endpoint
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// KeySetPublisher is implemented by makers whose tokens can be verified with public keys
+type KeySetPublisher interface {
+	JWKS() JSONWebKeySet
+}
+
+// JWKS returns the public keys of every key that is accepted for verification
+func (maker *AsymmetricJWTMaker) JWKS() JSONWebKeySet {
+	kids := make([]string, 0, len(maker.keys))
+	for kid := range maker.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	set := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(kids))}
+	for _, kid := range kids {
+		key := maker.keys[kid]
+		jwk := JSONWebKey{
+			KeyID:     key.id,
+			Algorithm: key.method.Alg(),
+			Use:       "sig",
+		}
+
+		switch publicKey := key.publicKey.(type) {
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		case *ecdsa.PublicKey:
+			size := (publicKey.Curve.Params().BitSize + 7) / 8
+			jwk.KeyType = "EC"
+			jwk.Curve = publicKey.Curve.Params().Name
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey.X.FillBytes(make([]byte, size)))
+			jwk.Y = base64.RawURLEncoding.EncodeToString(publicKey.Y.FillBytes(make([]byte, size)))
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
@@ -0,0 +1,216 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/gurukanth/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func writeEd25519Key(t *testing.T, dir string, kid string) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	writePKCS8Key(t, dir, kid, privateKey)
+}
+
+func writeES256Key(t *testing.T, dir string, kid string) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	writePKCS8Key(t, dir, kid, privateKey)
+}
+
+func writePKCS8Key(t *testing.T, dir string, kid string, privateKey any) {
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	err = os.WriteFile(filepath.Join(dir, kid+keyFileExt), data, 0600)
+	require.NoError(t, err)
+}
+
+func TestAsymmetricJWTMakerEd25519(t *testing.T) {
+	dir := t.TempDir()
+	writeEd25519Key(t, dir, "2026-01")
+
+	maker, err := NewAsymmetricJWTMaker(dir, "")
+	require.NoError(t, err)
+
+	token := requireValidToken(t, maker)
+	requireTokenHeader(t, token, "EdDSA", "2026-01")
+}
+
+func TestAsymmetricJWTMakerES256(t *testing.T) {
+	dir := t.TempDir()
+	writeES256Key(t, dir, "2026-01")
+
+	maker, err := NewAsymmetricJWTMaker(dir, "")
+	require.NoError(t, err)
+
+	token := requireValidToken(t, maker)
+	requireTokenHeader(t, token, "ES256", "2026-01")
+}
+
+func TestExpiredAsymmetricJWTToken(t *testing.T) {
+	dir := t.TempDir()
+	writeEd25519Key(t, dir, "2026-01")
+
+	maker, err := NewAsymmetricJWTMaker(dir, "")
+	require.NoError(t, err)
+
+	requireExpiredToken(t, maker)
+}
+
+func TestTamperedAsymmetricJWTToken(t *testing.T) {
+	dir := t.TempDir()
+	writeES256Key(t, dir, "2026-01")
+
+	maker, err := NewAsymmetricJWTMaker(dir, "")
+	require.NoError(t, err)
+
+	requireTamperedTokenRejected(t, maker)
+}
+
+func TestAsymmetricJWTMakerKeyRotation(t *testing.T) {
+	dir := t.TempDir()
+	writeEd25519Key(t, dir, "2026-01")
+
+	oldMaker, err := NewAsymmetricJWTMaker(dir, "")
+	require.NoError(t, err)
+
+	oldToken, _, err := oldMaker.CreateToken(AccessToken, util.RandomOwner(), util.CustomerRole, uuid.Nil, time.Minute)
+	require.NoError(t, err)
+
+	// a new key takes over signing while the old one is still accepted
+	writeES256Key(t, dir, "2026-02")
+	maker, err := NewAsymmetricJWTMaker(dir, "")
+	require.NoError(t, err)
+
+	newToken, _, err := maker.CreateToken(AccessToken, util.RandomOwner(), util.CustomerRole, uuid.Nil, time.Minute)
+	require.NoError(t, err)
+	requireTokenHeader(t, newToken, "ES256", "2026-02")
+
+	_, err = maker.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	// the signing key can be pinned while a new key is being published
+	pinnedMaker, err := NewAsymmetricJWTMaker(dir, "2026-01")
+	require.NoError(t, err)
+
+	pinnedToken, _, err := pinnedMaker.CreateToken(AccessToken, util.RandomOwner(), util.CustomerRole, uuid.Nil, time.Minute)
+	require.NoError(t, err)
+	requireTokenHeader(t, pinnedToken, "EdDSA", "2026-01")
+
+	// retiring the old key rejects the tokens it signed
+	err = os.Rename(filepath.Join(dir, "2026-01"+keyFileExt), filepath.Join(dir, "2026-01.retired"))
+	require.NoError(t, err)
+
+	retiredMaker, err := NewAsymmetricJWTMaker(dir, "")
+	require.NoError(t, err)
+
+	payload, err := retiredMaker.VerifyToken(oldToken)
+	require.EqualError(t, err, ErrorInvalidToken.Error())
+	require.Nil(t, payload)
+
+	_, err = retiredMaker.VerifyToken(newToken)
+	require.NoError(t, err)
+}
+
+func TestAsymmetricJWTMakerRejectsOtherAlgorithms(t *testing.T) {
+	dir := t.TempDir()
+	writeEd25519Key(t, dir, "2026-01")
+
+	maker, err := NewAsymmetricJWTMaker(dir, "")
+	require.NoError(t, err)
+
+	hmacMaker, err := NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	token, _, err := hmacMaker.CreateToken(AccessToken, util.RandomOwner(), util.CustomerRole, uuid.Nil, time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.EqualError(t, err, ErrorInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestAsymmetricJWTMakerInvalidKeyDir(t *testing.T) {
+	_, err := NewAsymmetricJWTMaker(t.TempDir(), "")
+	require.Error(t, err)
+
+	dir := t.TempDir()
+	writeEd25519Key(t, dir, "2026-01")
+
+	_, err = NewAsymmetricJWTMaker(dir, "missing")
+	require.Error(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "broken"+keyFileExt), []byte("not a key"), 0600)
+	require.NoError(t, err)
+
+	_, err = NewAsymmetricJWTMaker(dir, "")
+	require.Error(t, err)
+}
+
+func TestAsymmetricJWTMakerJWKS(t *testing.T) {
+	dir := t.TempDir()
+	writeEd25519Key(t, dir, "2026-01")
+	writeES256Key(t, dir, "2026-02")
+
+	maker, err := NewAsymmetricJWTMaker(dir, "")
+	require.NoError(t, err)
+
+	keySet := maker.JWKS()
+	require.Len(t, keySet.Keys, 2)
+
+	edKey := keySet.Keys[0]
+	require.Equal(t, "2026-01", edKey.KeyID)
+	require.Equal(t, "OKP", edKey.KeyType)
+	require.Equal(t, "Ed25519", edKey.Curve)
+	require.Equal(t, "EdDSA", edKey.Algorithm)
+	require.Equal(t, "sig", edKey.Use)
+	require.Empty(t, edKey.Y)
+
+	ecKey := keySet.Keys[1]
+	require.Equal(t, "2026-02", ecKey.KeyID)
+	require.Equal(t, "EC", ecKey.KeyType)
+	require.Equal(t, "P-256", ecKey.Curve)
+	require.Equal(t, "ES256", ecKey.Algorithm)
+	require.NotEmpty(t, ecKey.Y)
+
+	// a downstream service can verify tokens with nothing but the published key
+	signer, err := NewAsymmetricJWTMaker(dir, "2026-01")
+	require.NoError(t, err)
+
+	token, _, err := signer.CreateToken(AccessToken, util.RandomOwner(), util.CustomerRole, uuid.Nil, time.Minute)
+	require.NoError(t, err)
+
+	x, err := base64.RawURLEncoding.DecodeString(edKey.X)
+	require.NoError(t, err)
+
+	_, err = jwt.Parse(token, func(*jwt.Token) (interface{}, error) {
+		return ed25519.PublicKey(x), nil
+	}, jwt.WithValidMethods([]string{"EdDSA"}))
+	require.NoError(t, err)
+}
+
+func requireTokenHeader(t *testing.T, token string, alg string, kid string) {
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+
+	jwtToken, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	require.NoError(t, err)
+	require.Equal(t, alg, jwtToken.Header["alg"])
+	require.Equal(t, kid, jwtToken.Header["kid"])
+}
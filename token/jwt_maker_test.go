@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/gurukanth/simplebank/util"
 	"github.com/stretchr/testify/require"
 )
@@ -19,17 +21,54 @@ func TestJWTMaker(t *testing.T) {
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
 
-	token, err := maker.CreateToken(username, duration)
+	sessionID := uuid.New()
+	token, createdPayload, err := maker.CreateToken(AccessToken, username, util.TellerRole, sessionID, duration)
 	log.Println("error from create token call: ", err)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
+	require.NotEmpty(t, createdPayload)
 
 	payload, err := maker.VerifyToken(token)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
+	require.Equal(t, createdPayload.ID, payload.ID)
 
 	require.NotZero(t, payload.ID)
+	require.Equal(t, sessionID, payload.SessionID)
 	require.Equal(t, username, payload.Username)
+	require.Equal(t, util.TellerRole, payload.Role)
+	require.Equal(t, ScopesForRole(util.TellerRole), payload.Scopes)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
 }
+
+func TestExpiredJWTToken(t *testing.T) {
+	maker, err := NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	requireExpiredToken(t, maker)
+}
+
+func TestTamperedJWTToken(t *testing.T) {
+	maker, err := NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	requireTamperedTokenRejected(t, maker)
+}
+
+func TestJWTTokenAlgNone(t *testing.T) {
+	payload, err := NewPayload(AccessToken, util.RandomOwner(), util.AdminRole, uuid.Nil, time.Minute)
+	require.NoError(t, err)
+
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodNone, newJWTClaims(payload))
+	token, err := jwtToken.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	maker, err := NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	payload, err = maker.VerifyToken(token)
+	require.Error(t, err)
+	require.EqualError(t, err, ErrorInvalidToken.Error())
+	require.Nil(t, payload)
+}
@@ -1,10 +1,14 @@
 package token
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // Manages tokens
 type Maker interface {
-	CreateToken(username string, duration time.Duration) (string, error)
+	CreateToken(tokenType string, username string, role string, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error)
 
 	VerifyToken(token string) (*Payload, error)
 }
@@ -0,0 +1,94 @@
+package token
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// PasetoMaker is a PASETO v4 token maker. It issues v4.local tokens when
+// created with a symmetric key and v4.public tokens when created with an
+// Ed25519 secret key.
+type PasetoMaker struct {
+	local        bool
+	symmetricKey paseto.V4SymmetricKey
+	secretKey    paseto.V4AsymmetricSecretKey
+	publicKey    paseto.V4AsymmetricPublicKey
+}
+
+// NewPasetoMaker creates a v4.local maker from a symmetric key
+func NewPasetoMaker(symmetricKey string) (Maker, error) {
+	if len(symmetricKey) != chacha20poly1305.KeySize {
+		return nil, fmt.Errorf("invalid key size, must be exactly %d characters", chacha20poly1305.KeySize)
+	}
+
+	key, err := paseto.V4SymmetricKeyFromBytes([]byte(symmetricKey))
+	if err != nil {
+		return nil, err
+	}
+
+	return &PasetoMaker{local: true, symmetricKey: key}, nil
+}
+
+// NewPasetoPublicMaker creates a v4.public maker from a hex encoded Ed25519 secret key
+func NewPasetoPublicMaker(secretKeyHex string) (Maker, error) {
+	secretKey, err := paseto.NewV4AsymmetricSecretKeyFromHex(secretKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid asymmetric key: %w", err)
+	}
+
+	return &PasetoMaker{secretKey: secretKey, publicKey: secretKey.Public()}, nil
+}
+
+func (pm *PasetoMaker) CreateToken(tokenType string, username string, role string, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(tokenType, username, role, sessionID, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	claims, err := json.Marshal(payload)
+	if err != nil {
+		return "", payload, err
+	}
+
+	pasetoToken, err := paseto.NewTokenFromClaimsJSON(claims, nil)
+	if err != nil {
+		return "", payload, err
+	}
+
+	if pm.local {
+		return pasetoToken.V4Encrypt(pm.symmetricKey, nil), payload, nil
+	}
+	return pasetoToken.V4Sign(pm.secretKey, nil), payload, nil
+}
+
+func (pm *PasetoMaker) VerifyToken(token string) (*Payload, error) {
+	// expiry is checked by Payload.Valid so it is reported as ErrorExpiredToken
+	parser := paseto.NewParserWithoutExpiryCheck()
+
+	var pasetoToken *paseto.Token
+	var err error
+	if pm.local {
+		pasetoToken, err = parser.ParseV4Local(pm.symmetricKey, token, nil)
+	} else {
+		pasetoToken, err = parser.ParseV4Public(pm.publicKey, token, nil)
+	}
+	if err != nil {
+		return nil, ErrorInvalidToken
+	}
+
+	payload := &Payload{}
+	if err := json.Unmarshal(pasetoToken.ClaimsJSON(), payload); err != nil {
+		return nil, ErrorInvalidToken
+	}
+
+	if err := payload.Valid(); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
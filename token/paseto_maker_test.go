@@ -0,0 +1,155 @@
+package token
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/google/uuid"
+	"github.com/gurukanth/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasetoMaker(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	requireValidToken(t, maker)
+}
+
+func TestExpiredPasetoToken(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	requireExpiredToken(t, maker)
+}
+
+func TestTamperedPasetoToken(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	requireTamperedTokenRejected(t, maker)
+}
+
+func TestPasetoMakerWrongKey(t *testing.T) {
+	maker1, err := NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	maker2, err := NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	token, _, err := maker1.CreateToken(AccessToken, util.RandomOwner(), util.CustomerRole, uuid.Nil, time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker2.VerifyToken(token)
+	require.EqualError(t, err, ErrorInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestPasetoMakerInvalidKeySize(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(31))
+	require.Error(t, err)
+	require.Nil(t, maker)
+}
+
+func TestPasetoPublicMaker(t *testing.T) {
+	maker, err := NewPasetoPublicMaker(paseto.NewV4AsymmetricSecretKey().ExportHex())
+	require.NoError(t, err)
+
+	token := requireValidToken(t, maker)
+	require.True(t, strings.HasPrefix(token, "v4.public."))
+}
+
+func TestExpiredPasetoPublicToken(t *testing.T) {
+	maker, err := NewPasetoPublicMaker(paseto.NewV4AsymmetricSecretKey().ExportHex())
+	require.NoError(t, err)
+
+	requireExpiredToken(t, maker)
+}
+
+func TestTamperedPasetoPublicToken(t *testing.T) {
+	maker, err := NewPasetoPublicMaker(paseto.NewV4AsymmetricSecretKey().ExportHex())
+	require.NoError(t, err)
+
+	requireTamperedTokenRejected(t, maker)
+}
+
+func TestPasetoPublicMakerInvalidKey(t *testing.T) {
+	maker, err := NewPasetoPublicMaker("not-a-hex-key")
+	require.Error(t, err)
+	require.Nil(t, maker)
+}
+
+func TestPasetoLocalTokenRejectedByPublicMaker(t *testing.T) {
+	localMaker, err := NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	publicMaker, err := NewPasetoPublicMaker(paseto.NewV4AsymmetricSecretKey().ExportHex())
+	require.NoError(t, err)
+
+	token, _, err := localMaker.CreateToken(AccessToken, util.RandomOwner(), util.CustomerRole, uuid.Nil, time.Minute)
+	require.NoError(t, err)
+
+	payload, err := publicMaker.VerifyToken(token)
+	require.EqualError(t, err, ErrorInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func requireValidToken(t *testing.T, maker Maker) string {
+	username := util.RandomOwner()
+	duration := time.Minute
+
+	issuedAt := time.Now()
+	expiredAt := issuedAt.Add(duration)
+
+	sessionID := uuid.New()
+	token, createdPayload, err := maker.CreateToken(AccessToken, username, util.TellerRole, sessionID, duration)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, createdPayload)
+
+	payload, err := maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+	require.Equal(t, createdPayload.ID, payload.ID)
+
+	require.NotZero(t, payload.ID)
+	require.Equal(t, sessionID, payload.SessionID)
+	require.Equal(t, username, payload.Username)
+	require.Equal(t, util.TellerRole, payload.Role)
+	require.Equal(t, ScopesForRole(util.TellerRole), payload.Scopes)
+	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+
+	return token
+}
+
+func requireExpiredToken(t *testing.T, maker Maker) {
+	token, _, err := maker.CreateToken(AccessToken, util.RandomOwner(), util.CustomerRole, uuid.Nil, -time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+
+	payload, err := maker.VerifyToken(token)
+	require.Error(t, err)
+	require.EqualError(t, err, ErrorExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func requireTamperedTokenRejected(t *testing.T, maker Maker) {
+	token, _, err := maker.CreateToken(AccessToken, util.RandomOwner(), util.CustomerRole, uuid.Nil, time.Minute)
+	require.NoError(t, err)
+
+	// flip a character of the encoded body so the authentication tag no longer matches
+	i := len(token) - 10
+	replacement := byte('A')
+	if token[i] == replacement {
+		replacement = 'B'
+	}
+	tampered := token[:i] + string(replacement) + token[i+1:]
+
+	payload, err := maker.VerifyToken(tampered)
+	require.Error(t, err)
+	require.EqualError(t, err, ErrorInvalidToken.Error())
+	require.Nil(t, payload)
+}
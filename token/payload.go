@@ -2,6 +2,7 @@ package token
 
 import (
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,25 +13,50 @@ var (
 	ErrorInvalidToken error = errors.New("token is invalid")
 )
 
+// Types of token. Access tokens authorize API requests; refresh tokens are
+// only good for getting new access tokens.
+const (
+	AccessToken  = "access"
+	RefreshToken = "refresh"
+)
+
 type Payload struct {
 	ID        uuid.UUID `json:"id"`
+	Type      string    `json:"token_type"`
+	SessionID uuid.UUID `json:"session_id"`
 	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	Scopes    []string  `json:"scopes"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
 
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+// NewPayload creates a payload of the given type bound to the given session.
+// Access tokens carry the scopes of the role, refresh tokens none. When
+// sessionID is uuid.Nil the token starts a new session identified by its own
+// ID.
+func NewPayload(tokenType string, username string, role string, sessionID uuid.UUID, duration time.Duration) (*Payload, error) {
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
 	}
 
+	if sessionID == uuid.Nil {
+		sessionID = tokenID
+	}
+
 	payload := &Payload{
 		ID:        tokenID,
+		Type:      tokenType,
+		SessionID: sessionID,
 		Username:  username,
+		Role:      role,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
 	}
+	if tokenType == AccessToken {
+		payload.Scopes = ScopesForRole(role)
+	}
 
 	return payload, nil
 }
@@ -41,3 +67,8 @@ func (p *Payload) Valid() error {
 	}
 	return nil
 }
+
+// HasScope reports whether the token grants the scope
+func (p *Payload) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
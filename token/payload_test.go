@@ -0,0 +1,78 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gurukanth/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPayload(t *testing.T) {
+	sessionID := uuid.New()
+
+	payload, err := NewPayload(AccessToken, util.RandomOwner(), util.CustomerRole, sessionID, time.Minute)
+	require.NoError(t, err)
+	require.NotEqual(t, payload.ID, payload.SessionID)
+	require.Equal(t, sessionID, payload.SessionID)
+	require.Equal(t, util.CustomerRole, payload.Role)
+	require.Equal(t, AccessToken, payload.Type)
+}
+
+func TestNewRefreshPayloadHasNoScopes(t *testing.T) {
+	payload, err := NewPayload(RefreshToken, util.RandomOwner(), util.AdminRole, uuid.Nil, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, RefreshToken, payload.Type)
+	require.Empty(t, payload.Scopes)
+	require.False(t, payload.HasScope(ScopeAdmin))
+}
+
+func TestNewPayloadStartsSession(t *testing.T) {
+	payload, err := NewPayload(AccessToken, util.RandomOwner(), util.CustomerRole, uuid.Nil, time.Minute)
+	require.NoError(t, err)
+	require.NotZero(t, payload.ID)
+	require.Equal(t, payload.ID, payload.SessionID)
+}
+
+func TestPayloadScopes(t *testing.T) {
+	testCases := []struct {
+		role    string
+		granted []string
+		denied  []string
+	}{
+		{
+			role:    util.CustomerRole,
+			granted: []string{ScopeAccountsRead, ScopeAccountsWrite, ScopeTransfersRead, ScopeTransfersWrite},
+			denied:  []string{ScopeUsersRead, ScopeCashWrite, ScopeAdmin},
+		},
+		{
+			role:    util.TellerRole,
+			granted: []string{ScopeAccountsRead, ScopeTransfersWrite, ScopeUsersRead, ScopeCashWrite},
+			denied:  []string{ScopeAdmin},
+		},
+		{
+			role:    util.AdminRole,
+			granted: []string{ScopeAccountsRead, ScopeTransfersWrite, ScopeUsersRead, ScopeCashWrite, ScopeAdmin},
+		},
+		{
+			role:   "unknown",
+			denied: []string{ScopeAccountsRead, ScopeAccountsWrite, ScopeTransfersRead, ScopeTransfersWrite, ScopeUsersRead, ScopeCashWrite, ScopeAdmin},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.role, func(t *testing.T) {
+			payload, err := NewPayload(AccessToken, util.RandomOwner(), tc.role, uuid.Nil, time.Minute)
+			require.NoError(t, err)
+
+			for _, scope := range tc.granted {
+				require.True(t, payload.HasScope(scope), scope)
+			}
+			for _, scope := range tc.denied {
+				require.False(t, payload.HasScope(scope), scope)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package token
+
+import "github.com/gurukanth/simplebank/util"
+
+// Scopes granted to access tokens
+const (
+	ScopeAccountsRead   = "accounts:read"
+	ScopeAccountsWrite  = "accounts:write"
+	ScopeTransfersRead  = "transfers:read"
+	ScopeTransfersWrite = "transfers:write"
+	ScopeUsersRead      = "users:read"
+	ScopeCashWrite      = "cash:write"
+	ScopeAdmin          = "admin"
+)
+
+var customerScopes = []string{
+	ScopeAccountsRead,
+	ScopeAccountsWrite,
+	ScopeTransfersRead,
+	ScopeTransfersWrite,
+}
+
+var roleScopes = map[string][]string{
+	util.CustomerRole: customerScopes,
+	util.TellerRole:   append(append([]string{}, customerScopes...), ScopeUsersRead, ScopeCashWrite),
+	util.AdminRole:    append(append([]string{}, customerScopes...), ScopeUsersRead, ScopeCashWrite, ScopeAdmin),
+}
+
+// ScopesForRole returns the scopes granted to the role, or none for an unknown role
+func ScopesForRole(role string) []string {
+	return append([]string{}, roleScopes[role]...)
+}
@@ -0,0 +1,57 @@
+package util
+
+import (
+	"runtime/debug"
+	"sync"
+)
+
+// Build metadata, set at link time with
+//
+//	go build -ldflags "-X github.com/gurukanth/simplebank/util.Version=v1.2.3 ..."
+//
+// Values left empty fall back to the VCS information go build embeds.
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// BuildInfo describes the running binary
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+var readBuildInfo = sync.OnceValue(func() BuildInfo {
+	info := BuildInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.GoVersion = bi.GoVersion
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.BuildTime == "" {
+				info.BuildTime = setting.Value
+			}
+		}
+	}
+	return info
+})
+
+// GetBuildInfo returns the build metadata of the running binary
+func GetBuildInfo() BuildInfo {
+	return readBuildInfo()
+}
@@ -0,0 +1,186 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Config holds the configuration of the application.
+// Values are read from a config file and can be overridden by environment
+// variables of the same name.
+type Config struct {
+	DBDriver             string        `mapstructure:"DB_DRIVER"`
+	DBSource             string        `mapstructure:"DB_SOURCE"`
+	DBMaxOpenConns       int           `mapstructure:"DB_MAX_OPEN_CONNS"`
+	DBMaxIdleConns       int           `mapstructure:"DB_MAX_IDLE_CONNS"`
+	DBConnMaxLifetime    time.Duration `mapstructure:"DB_CONN_MAX_LIFETIME"`
+	DBConnectTimeout     time.Duration `mapstructure:"DB_CONNECT_TIMEOUT"`
+	MigrateOnStart       bool          `mapstructure:"MIGRATE_ON_START"`
+	ServerAddress        string        `mapstructure:"SERVER_ADDRESS"`
+	ShutdownTimeout      time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	TokenType            string        `mapstructure:"TOKEN_TYPE"`
+	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	TokenAsymmetricKey   string        `mapstructure:"TOKEN_ASYMMETRIC_KEY"`
+	TokenKeyDir          string        `mapstructure:"TOKEN_KEY_DIR"`
+	TokenSigningKeyID    string        `mapstructure:"TOKEN_SIGNING_KEY_ID"`
+	CursorSecret         string        `mapstructure:"CURSOR_SECRET"`
+	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	SessionCacheTTL      time.Duration `mapstructure:"SESSION_CACHE_TTL"`
+	TxMaxRetries         int           `mapstructure:"TX_MAX_RETRIES"`
+	TxRetryBackoff       time.Duration `mapstructure:"TX_RETRY_BACKOFF"`
+	CurrencyCacheTTL     time.Duration `mapstructure:"CURRENCY_CACHE_TTL"`
+	FxQuoteTTL           time.Duration `mapstructure:"FX_QUOTE_TTL"`
+	FxSpreadBps          int32         `mapstructure:"FX_SPREAD_BPS"`
+	ReconcileInterval    time.Duration `mapstructure:"RECONCILE_INTERVAL"`
+	ReconcileSettleDelay time.Duration `mapstructure:"RECONCILE_SETTLE_DELAY"`
+	SchedulerInterval    time.Duration `mapstructure:"SCHEDULER_INTERVAL"`
+	ScheduledRetryDelay  time.Duration `mapstructure:"SCHEDULED_RETRY_DELAY"`
+	ScheduledMaxFailures int32         `mapstructure:"SCHEDULED_MAX_FAILURES"`
+	LogLevel             string        `mapstructure:"LOG_LEVEL"`
+}
+
+var defaults = map[string]any{
+	"DB_DRIVER":              "postgres",
+	"DB_SOURCE":              "",
+	"DB_MAX_OPEN_CONNS":      25,
+	"DB_MAX_IDLE_CONNS":      25,
+	"DB_CONN_MAX_LIFETIME":   "5m",
+	"DB_CONNECT_TIMEOUT":     "30s",
+	"MIGRATE_ON_START":       false,
+	"SERVER_ADDRESS":         "0.0.0.0:8080",
+	"SHUTDOWN_TIMEOUT":       "15s",
+	"TOKEN_TYPE":             "paseto",
+	"TOKEN_SYMMETRIC_KEY":    "",
+	"TOKEN_ASYMMETRIC_KEY":   "",
+	"TOKEN_KEY_DIR":          "",
+	"TOKEN_SIGNING_KEY_ID":   "",
+	"CURSOR_SECRET":          "",
+	"ACCESS_TOKEN_DURATION":  "15m",
+	"REFRESH_TOKEN_DURATION": "24h",
+	"SESSION_CACHE_TTL":      "30s",
+	"TX_MAX_RETRIES":         3,
+	"TX_RETRY_BACKOFF":       "20ms",
+	"CURRENCY_CACHE_TTL":     "1m",
+	"FX_QUOTE_TTL":           "30s",
+	"FX_SPREAD_BPS":          50,
+	"RECONCILE_INTERVAL":     "1h",
+	"RECONCILE_SETTLE_DELAY": "1m",
+	"SCHEDULER_INTERVAL":     "1m",
+	"SCHEDULED_RETRY_DELAY":  "1h",
+	"SCHEDULED_MAX_FAILURES": 3,
+	"LOG_LEVEL":              "info",
+}
+
+// LoadConfig reads the configuration from path, then applies environment
+// overrides and validates the result.
+// path is either a config file (.env, .yaml or .yml) or a directory holding
+// app.env. A missing app.env is not an error, so the whole configuration can
+// come from the environment.
+func LoadConfig(path string) (config Config, err error) {
+	v := viper.New()
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+
+	switch ext := filepath.Ext(path); ext {
+	case ".env", ".yaml", ".yml":
+		v.SetConfigFile(path)
+		v.SetConfigType(strings.TrimPrefix(ext, "."))
+	default:
+		v.AddConfigPath(path)
+		v.SetConfigName("app")
+		v.SetConfigType("env")
+	}
+
+	v.AutomaticEnv()
+
+	if err = v.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			return config, fmt.Errorf("cannot read config file: %w", err)
+		}
+	}
+
+	if err = v.Unmarshal(&config); err != nil {
+		return config, fmt.Errorf("cannot decode config: %w", err)
+	}
+
+	if err = config.Validate(); err != nil {
+		return config, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return config, nil
+}
+
+// Validate checks that the configuration can be used to start the server.
+// All problems are reported at once.
+func (config Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(config.DBDriver != "", "DB_DRIVER is required")
+	check(config.DBSource != "", "DB_SOURCE is required")
+	check(config.DBMaxOpenConns >= 0, "DB_MAX_OPEN_CONNS must not be negative")
+	check(config.DBMaxIdleConns >= 0, "DB_MAX_IDLE_CONNS must not be negative")
+	check(config.DBMaxOpenConns == 0 || config.DBMaxIdleConns <= config.DBMaxOpenConns,
+		"DB_MAX_IDLE_CONNS (%d) must not exceed DB_MAX_OPEN_CONNS (%d)", config.DBMaxIdleConns, config.DBMaxOpenConns)
+	check(config.DBConnMaxLifetime >= 0, "DB_CONN_MAX_LIFETIME must not be negative")
+	check(config.DBConnectTimeout >= 0, "DB_CONNECT_TIMEOUT must not be negative")
+	check(config.ServerAddress != "", "SERVER_ADDRESS is required")
+	check(config.ShutdownTimeout > 0, "SHUTDOWN_TIMEOUT must be positive")
+
+	switch config.TokenType {
+	case "jwt":
+		check(len(config.TokenSymmetricKey) >= 32, "TOKEN_SYMMETRIC_KEY must be at least 32 characters for TOKEN_TYPE jwt")
+	case "paseto":
+		check(len(config.TokenSymmetricKey) == 32, "TOKEN_SYMMETRIC_KEY must be exactly 32 characters for TOKEN_TYPE paseto")
+	case "paseto-public":
+		check(config.TokenAsymmetricKey != "", "TOKEN_ASYMMETRIC_KEY is required for TOKEN_TYPE paseto-public")
+	case "jwt-asymmetric":
+		check(config.TokenKeyDir != "", "TOKEN_KEY_DIR is required for TOKEN_TYPE jwt-asymmetric")
+	default:
+		check(false, "TOKEN_TYPE %q is not one of jwt, paseto, paseto-public, jwt-asymmetric", config.TokenType)
+	}
+
+	// page cursors signed with a per-process key would break on every restart
+	// and differ between replicas
+	check(len(config.CursorSecret) >= 32, "CURSOR_SECRET must be at least 32 characters")
+
+	check(config.AccessTokenDuration > 0, "ACCESS_TOKEN_DURATION must be positive")
+	check(config.RefreshTokenDuration > config.AccessTokenDuration, "REFRESH_TOKEN_DURATION must be longer than ACCESS_TOKEN_DURATION")
+	check(config.SessionCacheTTL >= 0, "SESSION_CACHE_TTL must not be negative")
+	check(config.TxMaxRetries >= 0, "TX_MAX_RETRIES must not be negative")
+	check(config.TxRetryBackoff >= 0, "TX_RETRY_BACKOFF must not be negative")
+
+	check(config.CurrencyCacheTTL >= 0, "CURRENCY_CACHE_TTL must not be negative")
+	check(config.FxQuoteTTL > 0, "FX_QUOTE_TTL must be positive")
+	check(config.FxSpreadBps >= 0 && config.FxSpreadBps < 10000, "FX_SPREAD_BPS must be between 0 and 9999")
+	check(config.ReconcileInterval >= 0, "RECONCILE_INTERVAL must not be negative")
+	check(config.ReconcileSettleDelay >= 0, "RECONCILE_SETTLE_DELAY must not be negative")
+	check(config.SchedulerInterval >= 0, "SCHEDULER_INTERVAL must not be negative")
+	check(config.ScheduledRetryDelay > 0, "SCHEDULED_RETRY_DELAY must be positive")
+	check(config.ScheduledMaxFailures > 0, "SCHEDULED_MAX_FAILURES must be positive")
+
+	_, err := config.SlogLevel()
+	check(err == nil, "LOG_LEVEL %q is not one of debug, info, warn, error", config.LogLevel)
+
+	return errors.Join(errs...)
+}
+
+// SlogLevel returns LogLevel as a slog.Level
+func (config Config) SlogLevel() (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(config.LogLevel))
+	return level, err
+}
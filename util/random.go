@@ -46,9 +46,3 @@ func RandomEmail() string {
 func RandomMoney() int64 {
 	return 5000
 }
-
-func RandomCurrency() string {
-	currencies := []string{"INR", "EUR", "USD"}
-	n := len(currencies)
-	return currencies[rnd.Intn(n)]
-}
@@ -0,0 +1,8 @@
+package util
+
+// Roles a user can hold
+const (
+	CustomerRole = "customer"
+	TellerRole   = "teller"
+	AdminRole    = "admin"
+)